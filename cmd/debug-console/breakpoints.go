@@ -0,0 +1,187 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/rpc"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dradtke/debug-console/dap"
+)
+
+// addBreakpoint handles "break <file>:<line> [if <condition>]".
+func addBreakpoint(dapClient *rpc.Client, args []string) {
+	if len(args) == 0 {
+		fmt.Println("Usage: break <file>:<line> [if <condition>]")
+		return
+	}
+	path, line, err := parseLocation(args[0])
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	bp := dap.Breakpoint{Path: path, Line: line}
+	if len(args) > 2 && args[1] == "if" {
+		bp.Condition = strings.Join(args[2:], " ")
+	} else if len(args) > 1 {
+		fmt.Println("Usage: break <file>:<line> [if <condition>]")
+		return
+	}
+
+	var result dap.Breakpoint
+	if err := dapClient.Call("DAPService.AddBreakpoint", bp, &result); err != nil {
+		log.Printf("Error adding breakpoint: %s", err)
+		return
+	}
+	fmt.Printf("Breakpoint %d at %s:%d\n", result.ID, result.Path, result.Line)
+}
+
+func listBreakpoints(dapClient *rpc.Client) {
+	var breakpoints []dap.Breakpoint
+	if err := dapClient.Call("DAPService.Breakpoints", struct{}{}, &breakpoints); err != nil {
+		log.Printf("Error listing breakpoints: %s", err)
+		return
+	}
+	if len(breakpoints) == 0 {
+		fmt.Println("No breakpoints")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tLocation\tStatus\tHits\tDetails")
+	for _, bp := range breakpoints {
+		var details []string
+		if bp.Condition != "" {
+			details = append(details, "if "+bp.Condition)
+		}
+		if bp.HitCondition != "" {
+			details = append(details, "hit "+bp.HitCondition)
+		}
+		if bp.LogMessage != "" {
+			details = append(details, "log "+strconv.Quote(bp.LogMessage))
+		}
+		if bp.Adapter != nil && bp.Adapter.Message != nil {
+			details = append(details, *bp.Adapter.Message)
+		}
+		fmt.Fprintf(w, "%d\t%s:%d\t%s\t%d\t%s\n", bp.ID, bp.Path, bp.EffectiveLine(), breakpointStatus(bp), bp.HitCount, strings.Join(details, ", "))
+	}
+	w.Flush()
+}
+
+func breakpointStatus(bp dap.Breakpoint) string {
+	switch {
+	case bp.Disabled:
+		return "disabled"
+	case bp.Adapter == nil:
+		return "pending"
+	case bp.Adapter.Verified:
+		return "verified"
+	default:
+		return "unverified"
+	}
+}
+
+// removeBreakpoints handles "delete <id|file:line...>".
+func removeBreakpoints(dapClient *rpc.Client, args []string) {
+	ids, err := breakpointIDs(dapClient, args)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if err := dapClient.Call("DAPService.RemoveBreakpoints", ids, nil); err != nil {
+		log.Printf("Error removing breakpoints: %s", err)
+	}
+}
+
+// enableBreakpoints handles "enable" and "disable", which take the same
+// arguments as "delete".
+func enableBreakpoints(dapClient *rpc.Client, args []string, enabled bool) {
+	ids, err := breakpointIDs(dapClient, args)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if err := dapClient.Call("DAPService.EnableBreakpoints", dap.EnableBreakpointsArgs{
+		IDs:     ids,
+		Enabled: enabled,
+	}, nil); err != nil {
+		log.Printf("Error updating breakpoints: %s", err)
+	}
+}
+
+// breakpointIDs resolves breakpoint arguments, which are either IDs or
+// <file>:<line> locations, to IDs. A location matches every breakpoint whose
+// effective line it is.
+func breakpointIDs(dapClient *rpc.Client, args []string) ([]int, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Must specify at least one breakpoint id or location")
+	}
+	var ids []int
+	for _, arg := range args {
+		if id, err := strconv.Atoi(arg); err == nil {
+			ids = append(ids, id)
+			continue
+		}
+		path, line, err := parseLocation(arg)
+		if err != nil {
+			return nil, err
+		}
+		var breakpoints []dap.Breakpoint
+		if err := dapClient.Call("DAPService.SourceBreakpoints", path, &breakpoints); err != nil {
+			return nil, fmt.Errorf("Error listing breakpoints: %w", err)
+		}
+		found := false
+		for _, bp := range breakpoints {
+			if bp.EffectiveLine() == line {
+				ids, found = append(ids, bp.ID), true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("No breakpoint at %s", arg)
+		}
+	}
+	return ids, nil
+}
+
+func clearBreakpoints(dapClient *rpc.Client) {
+	if err := dapClient.Call("DAPService.ClearBreakpoints", struct{}{}, nil); err != nil {
+		log.Printf("Error clearing breakpoints: %s", err)
+	}
+}
+
+// parseLocation parses a "<file>:<line>" location, making the file path absolute.
+func parseLocation(s string) (string, int, error) {
+	idx := strings.LastIndex(s, ":")
+	if idx == -1 {
+		return "", 0, fmt.Errorf("Invalid location, expected <file>:<line>: %s", s)
+	}
+	line, err := strconv.Atoi(s[idx+1:])
+	if err != nil || line < 1 {
+		return "", 0, fmt.Errorf("Invalid line number: %s", s[idx+1:])
+	}
+	path, err := filepath.Abs(s[:idx])
+	if err != nil {
+		return "", 0, fmt.Errorf("Invalid file: %w", err)
+	}
+	return path, line, nil
+}
+
+func parseIDs(args []string, kind string) ([]int, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("Must specify at least one %s id", kind)
+	}
+	ids := make([]int, 0, len(args))
+	for _, arg := range args {
+		id, err := strconv.Atoi(arg)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid %s id: %s", kind, arg)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
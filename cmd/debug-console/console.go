@@ -9,6 +9,7 @@ import (
 	"log"
 	"net"
 	"net/rpc"
+	"strconv"
 	"strings"
 
 	"github.com/chzyer/readline"
@@ -71,75 +72,72 @@ func runConsole(args []string) error {
 }
 
 func consoleInputLoop(c console.ConsoleService, dapClient *rpc.Client) error {
-	var (
-		multiline bool
-		lines     []string
-	)
+	var lines []string
+
+	fmt.Println("Running...")
 
-	// TODO: remove the non-interactivity
-	// For some reason doing that breaks the plugin?
+	// Input stays live while the program runs, so that it can be paused.
 	for {
-		// TODO: see if the program is running or not?
-		fmt.Println("Running...")
-		<-c.Stops
-
-	input:
-		for {
-			line, err := c.Prompt.Readline()
-			if err != nil {
-				if errors.Is(err, readline.ErrInterrupt) {
+		line, err := c.Prompt.Readline()
+		if err != nil {
+			if errors.Is(err, readline.ErrInterrupt) {
+				if c.Running() {
+					pause(dapClient, nil)
+				} else {
 					fmt.Println("Use Ctrl-D to quit")
-					continue input
 				}
-				if errors.Is(err, io.EOF) {
-					fmt.Println("Quitting...")
-					// Give the debug adapter a chance to terminate gracefully.
-					if err := dapClient.Call("DAPService.Terminate", struct{}{}, nil); err != nil {
-						// That failed, so just disconnect.
-						if err = dapClient.Call("DAPService.Disconnect", struct{}{}, nil); err != nil {
-							log.Printf("Error disconnecting from debug adapter: %s", err)
-						}
-					}
-					return nil
-				}
-				return err
+				continue
 			}
-			if multiline {
-				lines = append(lines, line)
-				if line != "" {
-					c.Prompt.SetPrompt("                *> ")
-					continue input
-				} else {
-					line = strings.Join(lines, "\n")
-					lines = nil
+			if errors.Is(err, io.EOF) {
+				fmt.Println("Quitting...")
+				// Give the debug adapter a chance to terminate gracefully.
+				if err := dapClient.Call("DAPService.Terminate", struct{}{}, nil); err != nil {
+					// That failed, so just disconnect.
+					if err = dapClient.Call("DAPService.Disconnect", struct{}{}, nil); err != nil {
+						log.Printf("Error disconnecting from debug adapter: %s", err)
+					}
 				}
+				return nil
 			}
-			// TODO: how to handle multiline-switching command?
-			keepLooping, toggleMultiline := handleCommand(line, dapClient)
-			if !keepLooping {
-				break input
-			}
-			if toggleMultiline {
-				multiline = !multiline
-			}
-			if multiline {
-				c.Prompt.SetPrompt("debug (multiline)> ")
+			return err
+		}
+		if c.Multiline() {
+			lines = append(lines, line)
+			if line != "" {
+				c.Prompt.SetPrompt("                *> ")
+				continue
 			} else {
-				c.Prompt.SetPrompt("debug> ")
+				line = strings.Join(lines, "\n")
+				lines = nil
 			}
 		}
+		// TODO: how to handle multiline-switching command?
+		if toggleMultiline := handleCommand(line, dapClient); toggleMultiline {
+			c.SetMultiline(!c.Multiline())
+		} else {
+			c.ResetPrompt()
+		}
 	}
 }
 
-func handleCommand(line string, dapClient *rpc.Client) (keepLooping, toggleMultiline bool) {
+// handleCommand runs a single console command, and reports whether multiline
+// mode should be toggled.
+func handleCommand(line string, dapClient *rpc.Client) (toggleMultiline bool) {
 	words := strings.Split(line, " ")
-	switch words[0] {
+	command, format, ok := splitFormat(dapClient, words[0])
+	if !ok {
+		return false
+	}
+	switch command {
+	case "":
+		return false
+
 	case "?", "h", "help":
 		help()
-		return true, false
+		return false
 
 	case "ml", "multiline":
-		return true, true
+		return true
 
 	case "caps", "capabilities":
 		var capabilities types.Capabilities
@@ -150,50 +148,101 @@ func handleCommand(line string, dapClient *rpc.Client) (keepLooping, toggleMulti
 		} else {
 			fmt.Println(string(b))
 		}
-		return true, false
+		return false
 
 	case "threads":
-		var threads []types.Thread
+		var (
+			threads  []types.Thread
+			selected int
+		)
 		if err := dapClient.Call("DAPService.Threads", struct{}{}, &threads); err != nil {
 			log.Printf("Error calling threads: %s", err)
+		} else if err := dapClient.Call("DAPService.SelectedThread", struct{}{}, &selected); err != nil {
+			log.Printf("Error getting selected thread: %s", err)
 		} else {
 			for _, thread := range threads {
-				fmt.Printf("[%d] %s\n", thread.ID, thread.Name)
+				marker := " "
+				if thread.ID == selected {
+					marker = "*"
+				}
+				fmt.Printf("%s [%d] %s\n", marker, thread.ID, thread.Name)
+			}
+		}
+		return false
+
+	case "thread":
+		if len(words) < 2 {
+			var selected int
+			if err := dapClient.Call("DAPService.SelectedThread", struct{}{}, &selected); err != nil {
+				log.Printf("Error getting selected thread: %s", err)
+			} else {
+				fmt.Printf("Selected thread: %d\n", selected)
 			}
+			return false
+		}
+		threadID, err := strconv.Atoi(words[1])
+		if err != nil {
+			fmt.Printf("Invalid thread id: %s\n", words[1])
+			return false
+		}
+		if err := dapClient.Call("DAPService.SelectThread", threadID, nil); err != nil {
+			log.Printf("Error selecting thread: %s", err)
 		}
-		return true, false
+		return false
+
+	case "kill-thread":
+		threadIDs, err := parseIDs(words[1:], "thread")
+		if err != nil {
+			fmt.Println(err)
+			return false
+		}
+		if err := dapClient.Call("DAPService.TerminateThreads", threadIDs, nil); err != nil {
+			log.Printf("Error calling terminateThreads: %s", err)
+		}
+		return false
 
 	case "c", "cont", "continue":
 		if err := dapClient.Call("DAPService.Continue", struct{}{}, nil); err != nil {
 			log.Printf("Error calling continue: %s", err)
 		}
-		return false, false
+		return false
+
+	case "rc", "reverse-continue":
+		if err := dapClient.Call("DAPService.ReverseContinue", struct{}{}, nil); err != nil {
+			log.Printf("Error calling reverseContinue: %s", err)
+		}
+		return false
+
+	case "rs", "reverse-step":
+		stepBack(dapClient, words[1:])
+		return false
+
+	case "pause":
+		pause(dapClient, words[1:])
+		return false
 
 	case "step":
 		if len(words) < 2 {
-			fmt.Println("Must specify 'in', 'out', or 'back'")
-			return true, false
+			fmt.Println("Must specify 'in', 'into', 'out', or 'back'")
+			return false
 		}
 		switch stepDir := words[1]; stepDir {
 		case "in":
 			if err := dapClient.Call("DAPService.StepIn", struct{}{}, nil); err != nil {
 				log.Printf("Error calling stepIn: %s", err)
 			}
+		case "into":
+			stepInto(dapClient, words[2:])
 		case "out":
 			if err := dapClient.Call("DAPService.StepOut", struct{}{}, nil); err != nil {
 				log.Printf("Error calling stepOut: %s", err)
 			}
 		case "back":
-			// this will need more testing....
-			if err := dapClient.Call("DAPService.StepBack", struct{}{}, nil); err != nil {
-				log.Printf("Error calling stepBack: %s", err)
-			}
-			return true, false
+			stepBack(dapClient, words[2:])
 		default:
 			fmt.Printf("Unknown step direction: %s\n", stepDir)
-			return true, false
 		}
-		return false, false
+		return false
 
 	case "n", "next":
 		var granularity string
@@ -203,27 +252,172 @@ func handleCommand(line string, dapClient *rpc.Client) (keepLooping, toggleMulti
 		if err := dapClient.Call("DAPService.Next", granularity, nil); err != nil {
 			log.Printf("Error calling next: %s", err)
 		}
-		return false, false
+		return false
+
+	case "bt", "where":
+		backtrace(dapClient, words[1:], format)
+		return false
+
+	case "up":
+		moveFrame(dapClient, words[1:], 1)
+		return false
+
+	case "down":
+		moveFrame(dapClient, words[1:], -1)
+		return false
+
+	case "frame":
+		selectFrame(dapClient, words[1:])
+		return false
+
+	case "scopes":
+		listScopes(dapClient)
+		return false
+
+	case "locals":
+		showScope(dapClient, "locals", valueFormat(format))
+		return false
+
+	case "args":
+		showScope(dapClient, "arguments", valueFormat(format))
+		return false
+
+	case "vars":
+		showVariables(dapClient, words[1:], valueFormat(format))
+		return false
+
+	case "set":
+		setValue(dapClient, strings.Join(words[1:], " "))
+		return false
+
+	case "watch":
+		watch(dapClient, words[1:])
+		return false
+
+	case "restart-frame":
+		restartFrame(dapClient, words[1:])
+		return false
+
+	case "x":
+		examineMemory(dapClient, words[1:])
+		return false
+
+	case "poke":
+		pokeMemory(dapClient, words[1:])
+		return false
+
+	case "disas", "disassemble":
+		disassemble(dapClient, words[1:])
+		return false
+
+	case "format":
+		setFormat(dapClient, words[1:])
+		return false
+
+	case "modules":
+		listModules(dapClient)
+		return false
+
+	case "sources":
+		listSources(dapClient)
+		return false
+
+	case "b", "break":
+		addBreakpoint(dapClient, words[1:])
+		return false
+
+	case "bps", "breakpoints":
+		listBreakpoints(dapClient)
+		return false
+
+	case "delete":
+		removeBreakpoints(dapClient, words[1:])
+		return false
+
+	case "disable":
+		enableBreakpoints(dapClient, words[1:], false)
+		return false
+
+	case "enable":
+		enableBreakpoints(dapClient, words[1:], true)
+		return false
+
+	case "clear":
+		clearBreakpoints(dapClient)
+		return false
 
 	case "e", "eval", "evaluate":
-		evaluate(dapClient, strings.Join(words[1:], " "))
-		return true, false
+		evaluate(dapClient, strings.Join(words[1:], " "), valueFormat(format))
+		return false
 
 	default:
-		evaluate(dapClient, line)
-		return true, false
+		evaluate(dapClient, line, nil)
+		return false
 	}
 }
 
-func evaluate(dapClient *rpc.Client, expression string) {
-	var result string
+// pause handles "pause [all]".
+func pause(dapClient *rpc.Client, args []string) {
+	all := len(args) > 0 && args[0] == "all"
+	if err := dapClient.Call("DAPService.Pause", all, nil); err != nil {
+		log.Printf("Error calling pause: %s", err)
+	}
+}
+
+// stepBack handles "step back [granularity]" and its "rs" alias.
+func stepBack(dapClient *rpc.Client, args []string) {
+	var granularity string
+	if len(args) > 0 {
+		granularity = args[0]
+	}
+	if err := dapClient.Call("DAPService.StepBack", granularity, nil); err != nil {
+		log.Printf("Error calling stepBack: %s", err)
+	}
+}
+
+// stepInto handles "step into [n]". Without an argument it lists the available
+// targets, and otherwise steps into the chosen one.
+func stepInto(dapClient *rpc.Client, args []string) {
+	var targets []types.StepInTarget
+	if err := dapClient.Call("DAPService.StepInTargets", struct{}{}, &targets); err != nil {
+		log.Printf("Error calling stepInTargets: %s", err)
+		return
+	}
+	if len(targets) == 0 {
+		fmt.Println("No step-in targets")
+		return
+	}
+
+	if len(args) == 0 {
+		for i, target := range targets {
+			fmt.Printf("[%d] %s\n", i+1, target.Label)
+		}
+		fmt.Println("Use 'step into <n>' to step into a target")
+		return
+	}
+
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 || n > len(targets) {
+		fmt.Printf("Invalid target: %s\n", args[0])
+		return
+	}
+	if err := dapClient.Call("DAPService.StepInto", targets[n-1].ID, nil); err != nil {
+		log.Printf("Error calling stepIn: %s", err)
+	}
+}
+
+func evaluate(dapClient *rpc.Client, expression string, format *types.ValueFormat) {
+	var result types.EvaluateResponse
 	if err := dapClient.Call("DAPService.Evaluate", types.EvaluateArguments{
 		Expression: expression,
 		Context:    "repl",
+		Format:     format,
 	}, &result); err != nil {
 		log.Print(err)
+	} else if result.MemoryReference != nil && *result.MemoryReference != "" {
+		fmt.Printf("%s [mem %s]\n", result.Result, *result.MemoryReference)
 	} else {
-		fmt.Println(result)
+		fmt.Println(result.Result)
 	}
 }
 
@@ -234,12 +428,44 @@ func help() {
   ml, multiline                           Toggle multiline mode
   caps, capabilities                      View the DAP server's capabilities
   c, cont, continue                       Continue execution
+  pause [all], Ctrl-C                     Pause the selected thread, or all threads
   n, next [statement|line|instruction]    Next statement, line, or instruction (default: statement)
   step (in, out, back)                    Step in, out, or back
+  rs, reverse-step [granularity]          Step back (same as 'step back')
+  rc, reverse-continue                    Continue backwards to the previous breakpoint
+  step into [n]                           List the calls on this line, or step into one
+  bt, where [n]                           Show the stack, starting at frame n
+  up [n], down [n]                        Select the frame n levels up or down (default: 1)
+  frame [n]                               Show or select a frame
+  scopes                                  List the scopes of the selected frame
+  locals, args                            Show local variables or arguments
+  vars <ref> [start]                      Show the variables of a scope or variable
+  set <name|expr> = <value>               Change the value of a variable
+  watch add <expr>                        Evaluate an expression every time the program stops
+  watch remove <id...>                    Remove watch expressions
+  watch [list]                            Show watch expressions and their values
+  restart-frame [n]                       Restart a stack frame (default: the top frame)
   e, eval, evaluate [statement]           Evaluate a statement
+  format [hex|dec|raw|module|types...]    Show or change how values and stack frames are formatted
   threads                                 Show running threads
+  thread [id]                             Show or select the thread to step
+  kill-thread <id...>                     Terminate threads without ending the session
+  x <ref|expr> [count]                    Dump memory as hex and ASCII (default: 64 bytes)
+  poke <ref|expr> <hex bytes>             Write bytes to memory
+  disas [addr] [count]                    Disassemble at an address (default: the current instruction)
+  modules                                 List the modules loaded by the program
+  sources                                 List the sources loaded by the program
+  b, break <file>:<line> [if <cond>]      Add a breakpoint
+  bps, breakpoints                        List breakpoints
+  delete <id|file:line...>                Delete breakpoints
+  disable <id|file:line...>               Disable breakpoints
+  enable <id|file:line...>                Enable breakpoints
+  clear                                   Delete all breakpoints
 
 Unrecognized commands will be evaluated as a statement.
 
+eval, locals, args, vars and bt take format options for a single command, as
+in eval/x: x (hex), d (decimal), r (raw), m (module) and t (parameter types).
+
 `)
 }
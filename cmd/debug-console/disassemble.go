@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/rpc"
+
+	"github.com/dradtke/debug-console/dap"
+)
+
+// disassembleCount is the number of instructions that "disas" shows by
+// default.
+const disassembleCount = 20
+
+// disassemble handles "disas [addr] [count]", where addr can also be an
+// expression, and defaults to the selected frame's instruction pointer.
+func disassemble(dapClient *rpc.Client, args []string) {
+	target, count := dap.SplitCount(args, disassembleCount)
+
+	var disassembly dap.Disassembly
+	if err := dapClient.Call("DAPService.Disassemble", dap.DisassembleArgs{
+		Target: target,
+		Count:  count,
+	}, &disassembly); err != nil {
+		log.Printf("Error disassembling: %s", err)
+		return
+	}
+	lines, _ := disassembly.Lines()
+	if len(lines) == 0 {
+		fmt.Println("No instructions")
+		return
+	}
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/rpc"
+	"strings"
+
+	"github.com/dradtke/debug-console/dap"
+	"github.com/dradtke/debug-console/types"
+)
+
+// formatCommands are the commands that take format options, as in "eval/x".
+var formatCommands = map[string]bool{
+	"e": true, "eval": true, "evaluate": true,
+	"locals": true, "args": true, "vars": true,
+	"bt": true, "where": true,
+}
+
+// formatLetters are the single-letter format options.
+var formatLetters = map[rune]string{'x': "hex", 'd': "dec", 'r': "raw", 'm': "module", 't': "types"}
+
+// splitFormat splits format options off a command, as in "eval/x" or "bt/mt",
+// and applies them to the session's format. The format is nil if there are no
+// options, and ok is false if they're invalid.
+func splitFormat(dapClient *rpc.Client, word string) (command string, format *dap.Format, ok bool) {
+	command, letters, found := strings.Cut(word, "/")
+	if !found || !formatCommands[command] {
+		return word, nil, true
+	}
+
+	options := make([]string, 0, len(letters))
+	for _, letter := range letters {
+		option, valid := formatLetters[letter]
+		if !valid {
+			fmt.Printf("Unknown format option: %c\n", letter)
+			return command, nil, false
+		}
+		options = append(options, option)
+	}
+
+	var current dap.Format
+	if err := dapClient.Call("DAPService.Format", struct{}{}, &current); err != nil {
+		log.Printf("Error getting format: %s", err)
+		return command, nil, false
+	}
+	f, err := dap.ParseFormat(current, options)
+	if err != nil {
+		fmt.Println(err)
+		return command, nil, false
+	}
+	return command, &f, true
+}
+
+// valueFormat returns the value format to request, or nil for the session's.
+func valueFormat(f *dap.Format) *types.ValueFormat {
+	if f == nil {
+		return nil
+	}
+	return f.ValueFormat()
+}
+
+// setFormat handles "format [option...]", which shows or changes the session's
+// format.
+func setFormat(dapClient *rpc.Client, args []string) {
+	var current dap.Format
+	if err := dapClient.Call("DAPService.Format", struct{}{}, &current); err != nil {
+		log.Printf("Error getting format: %s", err)
+		return
+	}
+	if len(args) == 0 {
+		fmt.Printf("Format: %s\n", current)
+		return
+	}
+
+	f, err := dap.ParseFormat(current, args)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if err := dapClient.Call("DAPService.SetFormat", f, nil); err != nil {
+		log.Printf("Error setting format: %s", err)
+		return
+	}
+	fmt.Printf("Format: %s\n", f)
+}
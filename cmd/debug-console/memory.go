@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/rpc"
+	"os"
+	"strings"
+
+	"github.com/dradtke/debug-console/dap"
+)
+
+// memoryDumpSize is the number of bytes that "x" shows by default.
+const memoryDumpSize = 64
+
+// examineMemory handles "x <memoryReference|expr> [count]".
+func examineMemory(dapClient *rpc.Client, args []string) {
+	if len(args) == 0 {
+		fmt.Println("Usage: x <memoryReference|expr> [count]")
+		return
+	}
+	target, count := dap.SplitCount(args, memoryDumpSize)
+
+	var memory dap.Memory
+	if err := dapClient.Call("DAPService.ReadMemory", dap.ReadMemoryArgs{
+		Target: target,
+		Count:  count,
+	}, &memory); err != nil {
+		log.Printf("Error reading memory: %s", err)
+		return
+	}
+	if len(memory.Data) == 0 && memory.Unreadable == 0 {
+		fmt.Println("No memory")
+		return
+	}
+	dap.Hexdump(os.Stdout, memory)
+}
+
+// pokeMemory handles "poke <memoryReference|expr> <hex bytes>", where the bytes
+// can be given as one string or separately, like "dead beef" or "de ad be ef".
+func pokeMemory(dapClient *rpc.Client, args []string) {
+	if len(args) < 2 {
+		fmt.Println("Usage: poke <memoryReference|expr> <hex bytes>")
+		return
+	}
+	data, err := hex.DecodeString(strings.TrimPrefix(strings.Join(args[1:], ""), "0x"))
+	if err != nil || len(data) == 0 {
+		fmt.Printf("Invalid bytes: %s\n", strings.Join(args[1:], " "))
+		return
+	}
+
+	var written int
+	if err := dapClient.Call("DAPService.WriteMemory", dap.WriteMemoryArgs{
+		Target: args[0],
+		Data:   data,
+	}, &written); err != nil {
+		log.Printf("Error writing memory: %s", err)
+		return
+	}
+	fmt.Printf("Wrote %d of %d bytes\n", written, len(data))
+}
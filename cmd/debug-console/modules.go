@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/rpc"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dradtke/debug-console/types"
+)
+
+// listModules handles "modules", including any extra columns that the debug
+// adapter asks for.
+func listModules(dapClient *rpc.Client) {
+	var (
+		modules      []types.Module
+		capabilities types.Capabilities
+	)
+	if err := dapClient.Call("DAPService.Modules", struct{}{}, &modules); err != nil {
+		log.Printf("Error calling modules: %s", err)
+		return
+	}
+	if err := dapClient.Call("DAPService.Capabilities", struct{}{}, &capabilities); err != nil {
+		log.Printf("Error getting capabilities: %s", err)
+		return
+	}
+	if len(modules) == 0 {
+		fmt.Println("No modules")
+		return
+	}
+
+	header := []string{"ID", "Name", "Path"}
+	for _, column := range capabilities.AdditionalModuleColumns {
+		header = append(header, column.Label)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, m := range modules {
+		row := []string{m.ID, m.Name, m.Path}
+		for _, column := range capabilities.AdditionalModuleColumns {
+			row = append(row, m.Attributes[column.AttributeName])
+		}
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	w.Flush()
+}
+
+// listSources handles "sources".
+func listSources(dapClient *rpc.Client) {
+	var sources []types.Source
+	if err := dapClient.Call("DAPService.LoadedSources", struct{}{}, &sources); err != nil {
+		log.Printf("Error calling loadedSources: %s", err)
+		return
+	}
+	if len(sources) == 0 {
+		fmt.Println("No loaded sources")
+		return
+	}
+	for _, source := range sources {
+		fmt.Println(sourceLabel(source))
+	}
+}
+
+func sourceLabel(source types.Source) string {
+	switch {
+	case source.Path != nil && source.Name != nil:
+		return fmt.Sprintf("%s (%s)", *source.Name, *source.Path)
+	case source.Path != nil:
+		return *source.Path
+	case source.Name != nil:
+		return *source.Name
+	}
+	return "<unknown>"
+}
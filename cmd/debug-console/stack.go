@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/rpc"
+	"path/filepath"
+	"strconv"
+
+	"github.com/dradtke/debug-console/dap"
+	"github.com/dradtke/debug-console/types"
+)
+
+// backtracePageSize is the number of frames that "bt" shows at a time.
+const backtracePageSize = 20
+
+// backtrace handles "bt [n]", which shows a page of the selected thread's
+// stack starting at frame n.
+func backtrace(dapClient *rpc.Client, args []string, format *dap.Format) {
+	start, ok := frameArg(args, 0)
+	if !ok {
+		return
+	}
+
+	var (
+		stack    types.StackTraceResponse
+		selected int
+	)
+	if err := dapClient.Call("DAPService.StackTrace", dap.StackTraceArgs{StartFrame: start, Levels: backtracePageSize, Format: format}, &stack); err != nil {
+		log.Printf("Error calling stackTrace: %s", err)
+		return
+	}
+	if err := dapClient.Call("DAPService.SelectedFrame", struct{}{}, &selected); err != nil {
+		log.Printf("Error getting selected frame: %s", err)
+		return
+	}
+	if len(stack.StackFrames) == 0 {
+		fmt.Println("No stack frames")
+		return
+	}
+
+	for i, frame := range stack.StackFrames {
+		marker := " "
+		if start+i == selected {
+			marker = "*"
+		}
+		fmt.Printf("%s %s\n", marker, formatFrame(start+i, frame))
+	}
+
+	next := start + len(stack.StackFrames)
+	switch {
+	case stack.TotalFrames != nil && *stack.TotalFrames > next:
+		fmt.Printf("(%d more frames, use 'bt %d' to continue)\n", *stack.TotalFrames-next, next)
+	case stack.TotalFrames == nil && len(stack.StackFrames) == backtracePageSize:
+		fmt.Printf("(use 'bt %d' to continue)\n", next)
+	}
+}
+
+// moveFrame handles "up [n]" and "down [n]".
+func moveFrame(dapClient *rpc.Client, args []string, direction int) {
+	n, ok := frameArg(args, 1)
+	if !ok {
+		return
+	}
+	var result dap.FrameSelection
+	if err := dapClient.Call("DAPService.MoveFrame", n*direction, &result); err != nil {
+		log.Printf("Error selecting frame: %s", err)
+		return
+	}
+	fmt.Println(formatFrame(result.Index, result.Frame))
+}
+
+// selectFrame handles "frame [n]". Without an argument it shows the selected
+// frame.
+func selectFrame(dapClient *rpc.Client, args []string) {
+	method, n := "DAPService.MoveFrame", 0
+	if len(args) > 0 {
+		var ok bool
+		if n, ok = frameArg(args, 0); !ok {
+			return
+		}
+		method = "DAPService.SelectFrame"
+	}
+	var result dap.FrameSelection
+	if err := dapClient.Call(method, n, &result); err != nil {
+		log.Printf("Error selecting frame: %s", err)
+		return
+	}
+	fmt.Println(formatFrame(result.Index, result.Frame))
+}
+
+// restartFrame handles "restart-frame [n]", where n indexes the selected
+// thread's stack and defaults to the top frame.
+func restartFrame(dapClient *rpc.Client, args []string) {
+	n, ok := frameArg(args, 0)
+	if !ok {
+		return
+	}
+
+	var stack types.StackTraceResponse
+	if err := dapClient.Call("DAPService.StackTrace", dap.StackTraceArgs{StartFrame: n, Levels: 1}, &stack); err != nil {
+		log.Printf("Error calling stackTrace: %s", err)
+		return
+	}
+	if len(stack.StackFrames) == 0 {
+		fmt.Printf("No frame %d\n", n)
+		return
+	}
+	frame := stack.StackFrames[0]
+	if frame.CanRestart != nil && !*frame.CanRestart {
+		fmt.Printf("Frame %d (%s) can't be restarted\n", n, frame.Name)
+		return
+	}
+	if err := dapClient.Call("DAPService.RestartFrame", frame.ID, nil); err != nil {
+		log.Printf("Error calling restartFrame: %s", err)
+	}
+}
+
+// frameArg parses an optional non-negative frame count or index.
+func frameArg(args []string, def int) (int, bool) {
+	if len(args) == 0 {
+		return def, true
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 0 {
+		fmt.Printf("Invalid frame: %s\n", args[0])
+		return 0, false
+	}
+	return n, true
+}
+
+func formatFrame(n int, frame types.StackFrame) string {
+	location := "<unknown>"
+	if frame.Source != nil {
+		switch {
+		case frame.Source.Path != nil:
+			location = filepath.Base(*frame.Source.Path)
+		case frame.Source.Name != nil:
+			location = *frame.Source.Name
+		}
+	}
+	return fmt.Sprintf("#%-3d %s at %s:%d", n, frame.Name, location, frame.Line)
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/rpc"
+	"strconv"
+	"strings"
+
+	"github.com/dradtke/debug-console/dap"
+	"github.com/dradtke/debug-console/types"
+)
+
+const (
+	// variablesPageSize is the number of indexed children, such as slice
+	// elements, that are shown at a time.
+	variablesPageSize = 100
+	// variablesMaxDepth is how many levels of nested variables are expanded.
+	// Anything deeper can be shown with "vars <ref>".
+	variablesMaxDepth = 2
+)
+
+// listScopes handles "scopes".
+func listScopes(dapClient *rpc.Client) {
+	scopes, ok := getScopes(dapClient)
+	if !ok {
+		return
+	}
+	if len(scopes) == 0 {
+		fmt.Println("No scopes")
+		return
+	}
+	for _, scope := range scopes {
+		details := []string{fmt.Sprintf("vars %d", scope.VariablesReference)}
+		if scope.Expensive {
+			details = append(details, "expensive")
+		}
+		fmt.Printf("%s [%s]\n", scope.Name, strings.Join(details, ", "))
+	}
+}
+
+// showScope handles "locals" and "args", which show the variables of the
+// scope with the given presentation hint.
+func showScope(dapClient *rpc.Client, hint string, format *types.ValueFormat) {
+	scopes, ok := getScopes(dapClient)
+	if !ok {
+		return
+	}
+	for _, scope := range scopes {
+		if scope.PresentationHint == hint || strings.EqualFold(scope.Name, hint) {
+			printVariables(dapClient, scope.VariablesReference, scope.NamedVariables, scope.IndexedVariables, 0, format)
+			return
+		}
+	}
+	// Not every debug adapter sets presentation hints, so fall back to the
+	// first scope for locals.
+	if hint == "locals" && len(scopes) > 0 {
+		scope := scopes[0]
+		printVariables(dapClient, scope.VariablesReference, scope.NamedVariables, scope.IndexedVariables, 0, format)
+		return
+	}
+	fmt.Printf("No %s scope\n", hint)
+}
+
+// showVariables handles "vars <ref> [start]", where start is the first
+// indexed child to show. Indexed children are shown a page at a time, after
+// the named ones when starting from the beginning.
+func showVariables(dapClient *rpc.Client, args []string, format *types.ValueFormat) {
+	if len(args) == 0 {
+		fmt.Println("Usage: vars <ref> [start]")
+		return
+	}
+	ref, err := strconv.Atoi(args[0])
+	if err != nil || ref <= 0 {
+		fmt.Printf("Invalid variables reference: %s\n", args[0])
+		return
+	}
+	start := 0
+	if len(args) > 1 {
+		if start, err = strconv.Atoi(args[1]); err != nil || start < 0 {
+			fmt.Printf("Invalid start: %s\n", args[1])
+			return
+		}
+	}
+	if start == 0 {
+		variables, ok := getVariables(dapClient, types.VariablesArguments{VariablesReference: ref, Filter: "named", Format: format})
+		if !ok {
+			return
+		}
+		for _, v := range variables {
+			printVariable(dapClient, v, 0, format)
+		}
+	}
+	printIndexedVariables(dapClient, ref, start, -1, 0, format)
+}
+
+// setValue handles "set <name|expr> = <value>".
+func setValue(dapClient *rpc.Client, args string) {
+	target, value, ok := splitAssignment(args)
+	if !ok {
+		fmt.Println("Usage: set <name|expr> = <value>")
+		return
+	}
+
+	var result types.SetVariableResponse
+	if err := dapClient.Call("DAPService.SetValue", dap.SetValueArgs{Target: target, Value: value}, &result); err != nil {
+		log.Printf("Error setting value: %s", err)
+		return
+	}
+	if result.Type != "" {
+		fmt.Printf("%s (%s) = %s\n", target, result.Type, result.Value)
+	} else {
+		fmt.Printf("%s = %s\n", target, result.Value)
+	}
+}
+
+// splitAssignment splits "<target> = <value>" at the first " = ", since values
+// such as strings are more likely to contain one than targets are. Without
+// one, it splits at the first "=" that isn't part of a comparison, so that
+// "x=5" works too.
+func splitAssignment(s string) (string, string, bool) {
+	i, n := strings.Index(s, " = "), len(" = ")
+	if i < 0 {
+		i, n = assignmentIndex(s), 1
+	}
+	if i < 0 {
+		return "", "", false
+	}
+	target, value := strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+n:])
+	return target, value, target != "" && value != ""
+}
+
+// assignmentIndex returns the index of the first "=" in s that isn't part of
+// "==", "!=", "<=" or ">=", or -1 if there is none.
+func assignmentIndex(s string) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] != '=' {
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == '=' {
+			i++ // skip the second half of "=="
+			continue
+		}
+		if i > 0 && strings.ContainsRune("=!<>", rune(s[i-1])) {
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+func getScopes(dapClient *rpc.Client) ([]types.Scope, bool) {
+	var scopes []types.Scope
+	if err := dapClient.Call("DAPService.Scopes", struct{}{}, &scopes); err != nil {
+		log.Printf("Error calling scopes: %s", err)
+		return nil, false
+	}
+	return scopes, true
+}
+
+// printVariables prints the children of ref, expanding nested variables up to
+// variablesMaxDepth. If the number of indexed children is known, they're
+// requested separately a page at a time.
+func printVariables(dapClient *rpc.Client, ref int, named, indexed *int, depth int, format *types.ValueFormat) {
+	if indexed == nil || *indexed == 0 {
+		if variables, ok := getVariables(dapClient, types.VariablesArguments{VariablesReference: ref, Format: format}); ok {
+			for _, v := range variables {
+				printVariable(dapClient, v, depth, format)
+			}
+		}
+		return
+	}
+
+	if named == nil || *named > 0 {
+		variables, ok := getVariables(dapClient, types.VariablesArguments{VariablesReference: ref, Filter: "named", Format: format})
+		if !ok {
+			return
+		}
+		for _, v := range variables {
+			printVariable(dapClient, v, depth, format)
+		}
+	}
+	printIndexedVariables(dapClient, ref, 0, *indexed, depth, format)
+}
+
+// printIndexedVariables prints a page of ref's indexed children, starting at
+// start. If total is negative, the number of children isn't known.
+func printIndexedVariables(dapClient *rpc.Client, ref, start, total, depth int, format *types.ValueFormat) {
+	count := variablesPageSize
+	if total >= 0 && total-start < count {
+		count = total - start
+	}
+	variables, ok := getVariables(dapClient, types.VariablesArguments{
+		VariablesReference: ref,
+		Filter:             "indexed",
+		Start:              start,
+		Count:              count,
+		Format:             format,
+	})
+	if !ok {
+		return
+	}
+	for _, v := range variables {
+		printVariable(dapClient, v, depth, format)
+	}
+
+	indent, next := strings.Repeat("  ", depth), start+count
+	switch {
+	case total >= 0 && next < total:
+		fmt.Printf("%s... %d more, use 'vars %d %d'\n", indent, total-next, ref, next)
+	case total < 0 && len(variables) == count:
+		fmt.Printf("%s... use 'vars %d %d' for more\n", indent, ref, next)
+	}
+}
+
+func getVariables(dapClient *rpc.Client, args types.VariablesArguments) ([]types.Variable, bool) {
+	var variables []types.Variable
+	if err := dapClient.Call("DAPService.Variables", args, &variables); err != nil {
+		log.Printf("Error calling variables: %s", err)
+		return nil, false
+	}
+	return variables, true
+}
+
+func printVariable(dapClient *rpc.Client, v types.Variable, depth int, format *types.ValueFormat) {
+	var b strings.Builder
+	b.WriteString(strings.Repeat("  ", depth))
+	b.WriteString(v.Name)
+	if v.Type != "" {
+		fmt.Fprintf(&b, " (%s)", v.Type)
+	}
+	fmt.Fprintf(&b, " = %s", v.Value)
+
+	var details []string
+	if hint := v.PresentationHint; hint != nil {
+		if hint.Kind != "" {
+			details = append(details, hint.Kind)
+		}
+		details = append(details, hint.Attributes...)
+		if hint.Visibility != "" && hint.Visibility != "public" {
+			details = append(details, hint.Visibility)
+		}
+	}
+	expand := v.VariablesReference > 0 && depth < variablesMaxDepth && (v.PresentationHint == nil || !v.PresentationHint.Lazy)
+	if v.MemoryReference != nil && *v.MemoryReference != "" {
+		details = append(details, "mem "+*v.MemoryReference)
+	}
+	if v.VariablesReference > 0 && !expand {
+		details = append(details, fmt.Sprintf("vars %d", v.VariablesReference))
+	}
+	if len(details) > 0 {
+		fmt.Fprintf(&b, " [%s]", strings.Join(details, ", "))
+	}
+	fmt.Println(b.String())
+
+	if expand {
+		printVariables(dapClient, v.VariablesReference, v.NamedVariables, v.IndexedVariables, depth+1, format)
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/rpc"
+	"os"
+	"strings"
+
+	"github.com/dradtke/debug-console/console"
+	"github.com/dradtke/debug-console/dap"
+)
+
+// watch handles "watch add <expr>", "watch remove <id...>" and "watch list".
+func watch(dapClient *rpc.Client, args []string) {
+	if len(args) == 0 {
+		args = []string{"list"}
+	}
+	switch args[0] {
+	case "add":
+		expression := strings.Join(args[1:], " ")
+		if expression == "" {
+			fmt.Println("Usage: watch add <expr>")
+			return
+		}
+		var result dap.Watch
+		if err := dapClient.Call("DAPService.AddWatch", expression, &result); err != nil {
+			log.Printf("Error adding watch: %s", err)
+			return
+		}
+		console.FormatWatches(os.Stdout, []dap.Watch{result})
+
+	case "remove", "delete":
+		ids, err := parseIDs(args[1:], "watch")
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if err := dapClient.Call("DAPService.RemoveWatches", ids, nil); err != nil {
+			log.Printf("Error removing watches: %s", err)
+		}
+
+	case "list":
+		var watches []dap.Watch
+		if err := dapClient.Call("DAPService.Watches", struct{}{}, &watches); err != nil {
+			log.Printf("Error listing watches: %s", err)
+			return
+		}
+		if len(watches) == 0 {
+			fmt.Println("No watches")
+			return
+		}
+		console.FormatWatches(os.Stdout, watches)
+
+	default:
+		fmt.Println("Usage: watch (add <expr>|remove <id...>|list)")
+	}
+}
@@ -0,0 +1,56 @@
+package console
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dradtke/debug-console/types"
+)
+
+// ShowException prints the exception that stopped the program above the
+// prompt.
+func (c ConsoleService) ShowException(info types.ExceptionInfoResponse, _ *struct{}) error {
+	FormatException(c.Prompt.Stdout(), info)
+	return nil
+}
+
+// FormatException writes an exception's ID, description, break mode, and
+// details, including any inner exceptions.
+func FormatException(w io.Writer, info types.ExceptionInfoResponse) {
+	fmt.Fprintf(w, "Exception: %s", info.ExceptionID)
+	if info.BreakMode != "" {
+		fmt.Fprintf(w, " (break mode: %s)", info.BreakMode)
+	}
+	fmt.Fprintln(w)
+	if info.Description != "" {
+		fmt.Fprintf(w, "  %s\n", info.Description)
+	}
+	if info.Details != nil {
+		formatExceptionDetails(w, *info.Details, "  ")
+	}
+}
+
+func formatExceptionDetails(w io.Writer, details types.ExceptionDetails, indent string) {
+	typeName := details.FullTypeName
+	if typeName == "" {
+		typeName = details.TypeName
+	}
+	switch {
+	case typeName != "" && details.Message != "":
+		fmt.Fprintf(w, "%s%s: %s\n", indent, typeName, details.Message)
+	case typeName != "":
+		fmt.Fprintf(w, "%s%s\n", indent, typeName)
+	case details.Message != "":
+		fmt.Fprintf(w, "%s%s\n", indent, details.Message)
+	}
+	if details.StackTrace != "" {
+		for _, line := range strings.Split(strings.TrimRight(details.StackTrace, "\n"), "\n") {
+			fmt.Fprintf(w, "%s  %s\n", indent, line)
+		}
+	}
+	for _, inner := range details.InnerException {
+		fmt.Fprintf(w, "%sCaused by:\n", indent)
+		formatExceptionDetails(w, inner, indent+"  ")
+	}
+}
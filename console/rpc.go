@@ -3,6 +3,7 @@ package console
 import (
 	"net/rpc"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/chzyer/readline"
@@ -11,21 +12,69 @@ import (
 
 type ConsoleService struct {
 	Prompt *readline.Instance
-	Stops  chan struct{}
+	status *status
+}
+
+type status struct {
+	mu        sync.Mutex
+	running   bool
+	multiline bool
 }
 
 func NewConsole(dapClient *rpc.Client) (ConsoleService, error) {
-	rl, err := readline.New("debug> ")
+	rl, err := readline.New("running> ")
 	if err != nil {
 		return ConsoleService{}, err
 	}
 	rl.Config.AutoComplete = completer{dapClient}
 	return ConsoleService{
 		Prompt: rl,
-		Stops:  make(chan struct{}, 1),
+		status: &status{running: true},
 	}, nil
 }
 
+// Running reports whether the program is currently running, as opposed to
+// stopped.
+func (c ConsoleService) Running() bool {
+	c.status.mu.Lock()
+	defer c.status.mu.Unlock()
+	return c.status.running
+}
+
+func (c ConsoleService) SetRunning(running bool) {
+	c.status.mu.Lock()
+	c.status.running = running
+	c.status.mu.Unlock()
+	c.ResetPrompt()
+}
+
+func (c ConsoleService) Multiline() bool {
+	c.status.mu.Lock()
+	defer c.status.mu.Unlock()
+	return c.status.multiline
+}
+
+func (c ConsoleService) SetMultiline(multiline bool) {
+	c.status.mu.Lock()
+	c.status.multiline = multiline
+	c.status.mu.Unlock()
+	c.ResetPrompt()
+}
+
+// ResetPrompt sets the prompt to reflect the current state, and redraws it.
+func (c ConsoleService) ResetPrompt() {
+	c.status.mu.Lock()
+	prompt := "debug> "
+	if c.status.running {
+		prompt = "running> "
+	} else if c.status.multiline {
+		prompt = "debug (multiline)> "
+	}
+	c.status.mu.Unlock()
+	c.Prompt.SetPrompt(prompt)
+	c.Prompt.Refresh()
+}
+
 func (c ConsoleService) Stop(_ struct{}, _ *struct{}) error {
 	// Allow the function to return before exiting in order to avoid an "unexpected EOF" error.
 	go func() {
@@ -38,6 +87,11 @@ func (c ConsoleService) Stop(_ struct{}, _ *struct{}) error {
 }
 
 func (c ConsoleService) HandleStopped(_ struct{}, _ *struct{}) error {
-	c.Stops <- struct{}{}
+	c.SetRunning(false)
+	return nil
+}
+
+func (c ConsoleService) HandleContinued(_ struct{}, _ *struct{}) error {
+	c.SetRunning(true)
 	return nil
 }
@@ -0,0 +1,30 @@
+package console
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/dradtke/debug-console/dap"
+)
+
+// ShowWatches prints the watch expressions above the prompt.
+func (c ConsoleService) ShowWatches(watches []dap.Watch, _ *struct{}) error {
+	FormatWatches(c.Prompt.Stdout(), watches)
+	return nil
+}
+
+// FormatWatches writes one line per watch, marking the ones whose value
+// changed since the last stop with a "*".
+func FormatWatches(w io.Writer, watches []dap.Watch) {
+	for _, watch := range watches {
+		marker := " "
+		if watch.Changed {
+			marker = "*"
+		}
+		if watch.Error != "" {
+			fmt.Fprintf(w, "%s [%d] %s: %s\n", marker, watch.ID, watch.Expression, watch.Error)
+		} else {
+			fmt.Fprintf(w, "%s [%d] %s = %s\n", marker, watch.ID, watch.Expression, watch.Value)
+		}
+	}
+}
@@ -0,0 +1,605 @@
+package dap
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/dradtke/debug-console/types"
+)
+
+// Breakpoint is a user-defined source breakpoint, along with the debug
+// adapter's latest view of it.
+type Breakpoint struct {
+	ID           int    `json:"id"`
+	Path         string `json:"path"`
+	Line         int    `json:"line"`
+	Column       int    `json:"column,omitempty"`
+	Condition    string `json:"condition,omitempty"`
+	HitCondition string `json:"hitCondition,omitempty"`
+	LogMessage   string `json:"logMessage,omitempty"`
+	Disabled     bool   `json:"disabled,omitempty"`
+	// Temporary breakpoints are removed as soon as the program stops, and
+	// are never saved.
+	Temporary bool `json:"-"`
+	// RunTo is set while run-to-cursor is using an existing breakpoint as
+	// its target. The breakpoint is sent without its condition or log
+	// message until the program stops, so that it always stops.
+	RunTo bool `json:"-"`
+
+	// HitCount is the number of times the breakpoint has been hit in the
+	// current session.
+	HitCount int `json:"-"`
+
+	// Adapter is the debug adapter's view of the breakpoint. It is nil until
+	// the breakpoint has been sent in a setBreakpoints request.
+	Adapter *types.Breakpoint `json:"-"`
+}
+
+// EffectiveLine returns the line the debug adapter chose for the breakpoint,
+// falling back to the requested line.
+func (bp Breakpoint) EffectiveLine() int {
+	if bp.Adapter != nil && bp.Adapter.Line != nil {
+		return *bp.Adapter.Line
+	}
+	return bp.Line
+}
+
+// Verified reports whether the debug adapter was able to set the breakpoint.
+func (bp Breakpoint) Verified() bool {
+	return bp.Adapter != nil && bp.Adapter.Verified
+}
+
+// alwaysStops reports whether the breakpoint stops every time it is hit, which
+// isn't the case for disabled and conditional breakpoints, or logpoints.
+func (bp Breakpoint) alwaysStops() bool {
+	return !bp.Disabled && bp.Condition == "" && bp.HitCondition == "" && bp.LogMessage == ""
+}
+
+func (bp Breakpoint) sourceBreakpoint() types.SourceBreakpoint {
+	sourceBp := types.SourceBreakpoint{Line: bp.Line}
+	if !bp.RunTo {
+		sourceBp.Condition = bp.Condition
+		sourceBp.HitCondition = bp.HitCondition
+		sourceBp.LogMessage = bp.LogMessage
+	}
+	if bp.Column > 0 {
+		sourceBp.Column = types.PtrInt(bp.Column)
+	}
+	return sourceBp
+}
+
+// BreakpointStore is the source of truth for breakpoints, independent of any
+// editor. It is persisted as JSON so that breakpoints survive restarts.
+type BreakpointStore struct {
+	mu          sync.Mutex
+	filename    string
+	nextID      int
+	breakpoints []*Breakpoint
+	// sources holds the sources whose content comes from the debug adapter,
+	// keyed by the path the editor uses for them. Their references only last
+	// for a session, so their breakpoints aren't saved.
+	sources map[string]types.Source
+}
+
+// NewBreakpointStore creates a store that persists to filename. If filename is
+// empty, breakpoints are kept in memory only.
+func NewBreakpointStore(filename string) *BreakpointStore {
+	return &BreakpointStore{filename: filename, nextID: 1, sources: make(map[string]types.Source)}
+}
+
+// Load reads the store's file, if it exists.
+func (s *BreakpointStore) Load() error {
+	var breakpoints []*Breakpoint
+	if err := loadJSON(s.filename, &breakpoints); err != nil {
+		return fmt.Errorf("BreakpointStore.Load: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.breakpoints = breakpoints
+	for _, bp := range breakpoints {
+		if bp.ID >= s.nextID {
+			s.nextID = bp.ID + 1
+		}
+	}
+	return nil
+}
+
+// save writes the store's file. It must be called with the lock held.
+func (s *BreakpointStore) save() error {
+	saved := make([]*Breakpoint, 0, len(s.breakpoints))
+	for _, bp := range s.breakpoints {
+		if _, ok := s.sources[bp.Path]; !ok && !bp.Temporary {
+			saved = append(saved, bp)
+		}
+	}
+	if err := saveJSON(s.filename, saved); err != nil {
+		return fmt.Errorf("BreakpointStore.save: %w", err)
+	}
+	return nil
+}
+
+// Add creates a new breakpoint and returns it with its ID assigned.
+func (s *BreakpointStore) Add(bp Breakpoint) (Breakpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	bp.ID = s.nextID
+	s.nextID++
+	s.breakpoints = append(s.breakpoints, &bp)
+	return bp, s.save()
+}
+
+// Remove deletes the breakpoint with the given ID, and reports whether it existed.
+func (s *BreakpointStore) Remove(id int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, bp := range s.breakpoints {
+		if bp.ID == id {
+			s.breakpoints = append(s.breakpoints[:i], s.breakpoints[i+1:]...)
+			return true, s.save()
+		}
+	}
+	return false, nil
+}
+
+// Update applies f to the breakpoint with the given ID.
+func (s *BreakpointStore) Update(id int, f func(*Breakpoint)) (Breakpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, bp := range s.breakpoints {
+		if bp.ID == id {
+			f(bp)
+			return *bp, s.save()
+		}
+	}
+	return Breakpoint{}, fmt.Errorf("no breakpoint with id %d", id)
+}
+
+// Move changes the line of the breakpoint with the given ID. The debug
+// adapter's view of it is assumed to have moved along with it.
+func (s *BreakpointStore) Move(id, line int) error {
+	_, err := s.Update(id, func(bp *Breakpoint) {
+		bp.Line = line
+		if bp.Adapter != nil {
+			adapterBp := *bp.Adapter
+			adapterBp.Line = types.PtrInt(line)
+			bp.Adapter = &adapterBp
+		}
+	})
+	return err
+}
+
+// Get returns the breakpoint with the given ID.
+func (s *BreakpointStore) Get(id int) (Breakpoint, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, bp := range s.breakpoints {
+		if bp.ID == id {
+			return *bp, true
+		}
+	}
+	return Breakpoint{}, false
+}
+
+// At returns the breakpoint on the given line, matching either the requested
+// line or the one chosen by the debug adapter.
+func (s *BreakpointStore) At(path string, line int) (Breakpoint, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, bp := range s.breakpoints {
+		if bp.Path == path && (bp.Line == line || bp.EffectiveLine() == line) {
+			return *bp, true
+		}
+	}
+	return Breakpoint{}, false
+}
+
+// Source returns the breakpoints in a single source, ordered by line.
+func (s *BreakpointStore) Source(path string) []Breakpoint {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var breakpoints []Breakpoint
+	for _, bp := range s.breakpoints {
+		if bp.Path == path {
+			breakpoints = append(breakpoints, *bp)
+		}
+	}
+	sort.Slice(breakpoints, func(i, j int) bool { return breakpoints[i].Line < breakpoints[j].Line })
+	return breakpoints
+}
+
+// Paths returns the paths of all sources that have breakpoints.
+func (s *BreakpointStore) Paths() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seen := make(map[string]bool)
+	var paths []string
+	for _, bp := range s.breakpoints {
+		if !seen[bp.Path] {
+			seen[bp.Path] = true
+			paths = append(paths, bp.Path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Clear deletes every breakpoint, and returns the paths of the sources that
+// had any.
+func (s *BreakpointStore) Clear() ([]string, error) {
+	paths := s.Paths()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.breakpoints = nil
+	return paths, s.save()
+}
+
+// removeTemporary deletes every temporary breakpoint, and restores any that
+// run-to-cursor was using. It returns the paths of the sources that changed.
+func (s *BreakpointStore) removeTemporary() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var (
+		kept  []*Breakpoint
+		paths []string
+	)
+	for _, bp := range s.breakpoints {
+		if bp.Temporary {
+			paths = append(paths, bp.Path)
+			continue
+		}
+		if bp.RunTo {
+			bp.RunTo = false
+			paths = append(paths, bp.Path)
+		}
+		kept = append(kept, bp)
+	}
+	s.breakpoints = kept
+	return uniq(paths)
+}
+
+// SetAdapterSource records that path is the editor's name for a source whose
+// content comes from the debug adapter, so that its breakpoints are sent with
+// the source's reference instead of a path.
+func (s *BreakpointStore) SetAdapterSource(path string, source types.Source) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sources[path] = source
+}
+
+// AdapterSource returns the source recorded for path by SetAdapterSource.
+func (s *BreakpointStore) AdapterSource(path string) (types.Source, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	source, ok := s.sources[path]
+	return source, ok
+}
+
+// source returns the source to send to the debug adapter for path.
+func (s *BreakpointStore) source(path string) types.Source {
+	if source, ok := s.AdapterSource(path); ok {
+		return source
+	}
+	return types.Source{Path: types.PtrString(path)}
+}
+
+// forgetAdapterSources deletes the breakpoints in sources from the debug
+// adapter, along with the sources themselves, once the session ends. It
+// returns the paths of the sources that had any.
+func (s *BreakpointStore) forgetAdapterSources() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var (
+		kept  []*Breakpoint
+		paths []string
+	)
+	for _, bp := range s.breakpoints {
+		if _, ok := s.sources[bp.Path]; ok {
+			paths = append(paths, bp.Path)
+			continue
+		}
+		kept = append(kept, bp)
+	}
+	s.breakpoints = kept
+	s.sources = make(map[string]types.Source)
+	return uniq(paths)
+}
+
+// All returns every breakpoint, ordered by ID.
+func (s *BreakpointStore) All() []Breakpoint {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	breakpoints := make([]Breakpoint, 0, len(s.breakpoints))
+	for _, bp := range s.breakpoints {
+		breakpoints = append(breakpoints, *bp)
+	}
+	sort.Slice(breakpoints, func(i, j int) bool { return breakpoints[i].ID < breakpoints[j].ID })
+	return breakpoints
+}
+
+// setAdapterState records the debug adapter's response to a setBreakpoints
+// request for the given source, whose breakpoints are matched up by position
+// with those that were sent.
+func (s *BreakpointStore) setAdapterState(path string, sent []Breakpoint, result []types.Breakpoint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, bp := range s.breakpoints {
+		if bp.Path == path {
+			bp.Adapter = nil
+		}
+	}
+	for i, sentBp := range sent {
+		for _, bp := range s.breakpoints {
+			if bp.ID != sentBp.ID {
+				continue
+			}
+			if i < len(result) {
+				adapterBp := result[i]
+				bp.Adapter = &adapterBp
+			} else {
+				bp.Adapter = nil
+			}
+		}
+	}
+}
+
+// clearAdapterState forgets everything the debug adapter reported, which is
+// necessary once it exits.
+func (s *BreakpointStore) clearAdapterState() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, bp := range s.breakpoints {
+		bp.Adapter = nil
+		bp.HitCount = 0
+	}
+}
+
+// recordHits increments the hit count of the breakpoints with the given
+// adapter IDs.
+func (s *BreakpointStore) recordHits(adapterIDs []int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range adapterIDs {
+		for _, bp := range s.breakpoints {
+			if bp.Adapter != nil && bp.Adapter.ID != nil && *bp.Adapter.ID == id {
+				bp.HitCount++
+			}
+		}
+	}
+}
+
+// applyEvent applies a breakpoint event from the debug adapter.
+func (s *BreakpointStore) applyEvent(event types.BreakpointEvent) {
+	changed := event.Breakpoint
+	if changed.ID == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, bp := range s.breakpoints {
+		if bp.Adapter == nil || bp.Adapter.ID == nil || *bp.Adapter.ID != *changed.ID {
+			continue
+		}
+		if event.Reason == "removed" {
+			bp.Adapter = nil
+			return
+		}
+		// Events only need to contain the fields that changed.
+		if changed.Line == nil {
+			changed.Line = bp.Adapter.Line
+		}
+		if changed.Source == nil {
+			changed.Source = bp.Adapter.Source
+		}
+		bp.Adapter = &changed
+		return
+	}
+}
+
+// SyncBreakpoints sends the breakpoints for a source to the debug adapter, and
+// records its view of them.
+func (d *DAP) SyncBreakpoints(path string) error {
+	var (
+		breakpoints       []Breakpoint
+		sourceBreakpoints []types.SourceBreakpoint
+	)
+	for _, bp := range d.Breakpoints.Source(path) {
+		if !bp.Disabled {
+			breakpoints = append(breakpoints, bp)
+			sourceBreakpoints = append(sourceBreakpoints, bp.sourceBreakpoint())
+		}
+	}
+
+	d.RLock()
+	p := d.Conn
+	d.RUnlock()
+	if p == nil {
+		return errors.New("No process running")
+	}
+
+	result, err := p.SetBreakpoints(types.SetBreakpointArguments{
+		Source:      d.Breakpoints.source(path),
+		Breakpoints: sourceBreakpoints,
+	})
+	if err != nil {
+		return fmt.Errorf("Error setting breakpoints: %w", err)
+	}
+	d.Breakpoints.setAdapterState(path, breakpoints, result)
+	return nil
+}
+
+// BreakpointsChanged pushes the breakpoints for the given sources to the debug
+// adapter, if one is running, and lets the editor know about the change.
+func (d *DAP) BreakpointsChanged(paths ...string) error {
+	var errs []error
+	for _, path := range paths {
+		if d.HasSession() {
+			if err := d.SyncBreakpoints(path); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if d.EditorBreakpointsHandler != nil {
+			d.EditorBreakpointsHandler(path)
+		}
+	}
+	// TODO: use multierr or similar?
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// AddBreakpoint adds a new breakpoint.
+func (d *DAP) AddBreakpoint(bp Breakpoint) (Breakpoint, error) {
+	bp, err := d.Breakpoints.Add(bp)
+	if err != nil {
+		return bp, err
+	}
+	return bp, d.BreakpointsChanged(bp.Path)
+}
+
+// RemoveBreakpoints removes the breakpoints with the given IDs. Nothing is
+// removed unless every ID exists.
+func (d *DAP) RemoveBreakpoints(ids []int) error {
+	paths, err := d.breakpointPaths(ids)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if _, err := d.Breakpoints.Remove(id); err != nil {
+			d.BreakpointsChanged(paths...)
+			return err
+		}
+	}
+	return d.BreakpointsChanged(paths...)
+}
+
+// SetBreakpointsEnabled enables or disables the breakpoints with the given
+// IDs. Disabled breakpoints are kept, but not sent to the debug adapter.
+// Nothing is changed unless every ID exists.
+func (d *DAP) SetBreakpointsEnabled(ids []int, enabled bool) error {
+	paths, err := d.breakpointPaths(ids)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if _, err := d.Breakpoints.Update(id, func(bp *Breakpoint) {
+			bp.Disabled = !enabled
+		}); err != nil {
+			d.BreakpointsChanged(paths...)
+			return err
+		}
+	}
+	return d.BreakpointsChanged(paths...)
+}
+
+// breakpointPaths returns the paths of the sources of the breakpoints with the
+// given IDs, or an error if any of them don't exist.
+func (d *DAP) breakpointPaths(ids []int) ([]string, error) {
+	var paths []string
+	for _, id := range ids {
+		bp, ok := d.Breakpoints.Get(id)
+		if !ok {
+			return nil, fmt.Errorf("no breakpoint with id %d", id)
+		}
+		paths = append(paths, bp.Path)
+	}
+	return uniq(paths), nil
+}
+
+// ClearBreakpoints removes every breakpoint.
+func (d *DAP) ClearBreakpoints() error {
+	paths, err := d.Breakpoints.Clear()
+	if err != nil {
+		return err
+	}
+	return d.BreakpointsChanged(paths...)
+}
+
+// RunTo continues execution until the given line is reached, using a temporary
+// breakpoint that is removed on the next stop. If the line already has an
+// enabled breakpoint, that one is used instead, since debug adapters don't
+// allow two on the same line; a conditional breakpoint or logpoint is sent as
+// a plain breakpoint until the next stop.
+func (d *DAP) RunTo(path string, line int) error {
+	var (
+		existing Breakpoint
+		found    bool
+	)
+	for _, bp := range d.Breakpoints.Source(path) {
+		if (bp.Line == line || bp.EffectiveLine() == line) && !bp.Disabled {
+			existing, found = bp, true
+			break
+		}
+	}
+
+	switch {
+	case !found:
+		if _, err := d.AddBreakpoint(Breakpoint{Path: path, Line: line, Temporary: true}); err != nil {
+			return err
+		}
+	case !existing.alwaysStops():
+		if _, err := d.Breakpoints.Update(existing.ID, func(bp *Breakpoint) {
+			bp.RunTo = true
+		}); err != nil {
+			return err
+		}
+		if err := d.BreakpointsChanged(path); err != nil {
+			return err
+		}
+	}
+	return d.Continue()
+}
+
+// clearTemporaryBreakpoints removes temporary breakpoints, and restores the
+// ones run-to-cursor changed, once the program has stopped, whether or not it
+// was one of them that stopped it.
+func (d *DAP) clearTemporaryBreakpoints() {
+	if paths := d.Breakpoints.removeTemporary(); len(paths) > 0 {
+		if err := d.BreakpointsChanged(paths...); err != nil {
+			log.Printf("Error removing temporary breakpoints: %s", err)
+		}
+	}
+}
+
+func uniq(values []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// BreakpointLocations asks the debug adapter for the valid breakpoint
+// locations between line and endLine, inclusive. It returns
+// types.ErrUnsupported if there is no session, or if the debug adapter doesn't
+// support the request.
+func (d *DAP) BreakpointLocations(path string, line, endLine int) ([]types.BreakpointLocation, error) {
+	d.RLock()
+	p, capabilities := d.Conn, d.Capabilities
+	d.RUnlock()
+	if p == nil || capabilities == nil || !capabilities.SupportsBreakpointLocationsRequest {
+		return nil, types.ErrUnsupported
+	}
+
+	return p.BreakpointLocations(types.BreakpointLocationsArguments{
+		Source:  d.Breakpoints.source(path),
+		Line:    line,
+		EndLine: types.PtrInt(endLine),
+	})
+}
+
+// HasSession reports whether a debug adapter is currently running.
+func (d *DAP) HasSession() bool {
+	d.RLock()
+	defer d.RUnlock()
+	return d.Conn != nil
+}
@@ -0,0 +1,57 @@
+package dap_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/dradtke/debug-console/dap"
+	"github.com/dradtke/debug-console/types"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestBreakpointStorePersistence(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "breakpoints.json")
+
+	s := dap.NewBreakpointStore(filename)
+	if _, err := s.Add(dap.Breakpoint{Path: "/src/main.go", Line: 12}); err != nil {
+		t.Fatal(err)
+	}
+	bp, err := s.Add(dap.Breakpoint{Path: "/src/main.go", Line: 4, Condition: "i > 3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Add(dap.Breakpoint{Path: "/src/util.go", Line: 7, LogMessage: "x = {x}"}); err != nil {
+		t.Fatal(err)
+	}
+	// Breakpoints in sources from the debug adapter only last for a session.
+	s.SetAdapterSource("debug-console://source/5/Main.java", types.Source{SourceReference: types.PtrInt(5)})
+	if _, err := s.Add(dap.Breakpoint{Path: "debug-console://source/5/Main.java", Line: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Remove(bp.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := dap.NewBreakpointStore(filename)
+	if err := loaded.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff([]string{"/src/main.go", "/src/util.go"}, loaded.Paths()); diff != "" {
+		t.Errorf("paths mismatch (-want +got):\n%s", diff)
+	}
+
+	want := []dap.Breakpoint{{ID: 3, Path: "/src/util.go", Line: 7, LogMessage: "x = {x}"}}
+	if diff := cmp.Diff(want, loaded.Source("/src/util.go"), cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("breakpoints mismatch (-want +got):\n%s", diff)
+	}
+
+	added, err := loaded.Add(dap.Breakpoint{Path: "/src/util.go", Line: 9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added.ID != 4 {
+		t.Errorf("unexpected id for new breakpoint: %d", added.ID)
+	}
+}
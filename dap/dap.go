@@ -32,6 +32,36 @@ type DAP struct {
 
 	StoppedLocation *types.StackFrame
 	StoppedThreadID int
+	// SelectedThreadID is the thread that stepping requests apply to. It
+	// follows the stopped thread unless the user picks a different one.
+	SelectedThreadID int
+	// threads caches the thread list, which is refreshed by thread events.
+	threads []types.Thread
+	// terminatedThreads holds the threads ended by TerminateThreads whose
+	// exit hasn't been reported yet.
+	terminatedThreads map[int]bool
+	// modules and sources cache what the program has loaded, and are
+	// refreshed by module and loadedSource events.
+	modules []types.Module
+	sources []types.Source
+	// SelectedFrame is the frame that expressions are evaluated in, and
+	// SelectedFrameIndex is its position in the selected thread's stack.
+	SelectedFrame      *types.StackFrame
+	SelectedFrameIndex int
+	// StoppedException describes the exception that caused the latest stop,
+	// if any.
+	StoppedException *types.ExceptionInfoResponse
+	// Format is how values and stack frames are formatted, unless a request
+	// asks for something else.
+	Format Format
+
+	Breakpoints *BreakpointStore
+	Watches     *WatchStore
+	// EditorBreakpointsHandler is called with the path of a source whenever
+	// its breakpoints change.
+	EditorBreakpointsHandler func(path string)
+	// EditorFrameHandler is called whenever a different frame is selected.
+	EditorFrameHandler func(frame types.StackFrame)
 }
 
 type DapCommandFunc func(string) ([]string, error)
@@ -185,39 +215,34 @@ func (d *DAP) SendRequest(req types.Request) (types.Response, error) {
 func (d *DAP) ClearProcess() {
 	d.Lock()
 	d.Conn = nil
+	d.threads, d.modules, d.sources = nil, nil, nil
+	d.terminatedThreads = nil
 	d.Unlock()
+	d.Breakpoints.removeTemporary()
+	d.Breakpoints.clearAdapterState()
+	d.Watches.reset()
+	if paths := d.Breakpoints.forgetAdapterSources(); len(paths) > 0 {
+		d.BreakpointsChanged(paths...)
+	}
 }
 
 func (d *DAP) HandleStopped(stopped types.StoppedEvent) (*types.StackFrame, error) {
-	if err := d.ConsoleClient.Call("ConsoleService.HandleStopped", struct{}{}, nil); err != nil {
-		log.Printf("Error invoking ConsoleService.HandleStopped: %s", err)
-	}
+	d.notifyConsole("ConsoleService.HandleStopped")
 	if stopped.ThreadID == nil {
 		return nil, nil
 	}
 
 	d.Lock()
 	d.StoppedThreadID = *stopped.ThreadID
+	d.SelectedThreadID = *stopped.ThreadID
+	d.StoppedException = nil
 	d.Unlock()
 
-	resp, err := d.SendRequest(types.NewStackTraceRequest(types.StackTraceArguments{
-		ThreadID: *stopped.ThreadID,
-		Levels:   1,
-		Format: &types.StackFrameFormat{
-			Line: types.PtrBool(true),
-		},
-	}))
+	body, err := d.stackTrace(*stopped.ThreadID, 0, 1, nil)
 	if err != nil {
 		return nil, fmt.Errorf("Error getting stack trace: %w", err)
 	}
 
-	var body struct {
-		StackFrames []types.StackFrame `json:"stackFrames"`
-	}
-	if err := json.Unmarshal(resp.Body, &body); err != nil {
-		return nil, fmt.Errorf("Error parsing stackTrace response: %w", err)
-	}
-
 	if len(body.StackFrames) == 0 {
 		return nil, nil
 	}
@@ -225,8 +250,13 @@ func (d *DAP) HandleStopped(stopped types.StoppedEvent) (*types.StackFrame, erro
 	stackFrame := body.StackFrames[0]
 	d.Lock()
 	d.StoppedLocation = &stackFrame
+	d.SelectedFrame, d.SelectedFrameIndex = &stackFrame, 0
 	log.Printf("Stopped at: %+v", d.StoppedLocation)
 	d.Unlock()
+	if stopped.Reason == "exception" {
+		d.showException(*stopped.ThreadID)
+	}
+	d.showWatches()
 	return &stackFrame, nil
 }
 
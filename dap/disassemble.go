@@ -0,0 +1,124 @@
+package dap
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dradtke/debug-console/types"
+)
+
+// Disassembly is a run of disassembled instructions.
+type Disassembly struct {
+	Instructions []types.DisassembledInstruction
+	// InstructionPointer is the selected frame's instruction pointer
+	// reference, if it has one.
+	InstructionPointer string
+}
+
+// Disassemble disassembles count instructions starting at target, which is a
+// memory reference or an expression that has one. If target is empty, the
+// instructions around the selected frame's instruction pointer are
+// disassembled instead, including a few leading up to it.
+func (d *DAP) Disassemble(target string, count int) (Disassembly, error) {
+	d.RLock()
+	p, capabilities, frame := d.Conn, d.Capabilities, d.SelectedFrame
+	d.RUnlock()
+	if capabilities == nil || !capabilities.SupportsDisassembleRequest {
+		return Disassembly{}, types.ErrUnsupported
+	}
+	if p == nil {
+		return Disassembly{}, errors.New("No process running")
+	}
+
+	var disassembly Disassembly
+	if frame != nil && frame.InstructionPointerReference != nil {
+		disassembly.InstructionPointer = *frame.InstructionPointerReference
+	}
+
+	args := types.DisassembleArguments{InstructionCount: count, ResolveSymbols: true}
+	if target != "" {
+		ref, err := d.MemoryReference(target)
+		if err != nil {
+			return disassembly, err
+		}
+		args.MemoryReference = ref
+	} else if disassembly.InstructionPointer != "" {
+		args.MemoryReference = disassembly.InstructionPointer
+		args.InstructionOffset = -count / 4
+	} else if frame == nil {
+		return disassembly, errors.New("not stopped")
+	} else {
+		return disassembly, errors.New("The selected frame has no instruction pointer")
+	}
+
+	resp, err := p.Disassemble(args)
+	if err != nil {
+		return disassembly, err
+	}
+	disassembly.Instructions = resp.Instructions
+	return disassembly, nil
+}
+
+// Lines formats the instructions as aligned columns of address, instruction
+// bytes, instruction, symbol and source location. It also returns the index of
+// the line at the instruction pointer, or -1 if it isn't included.
+func (dis Disassembly) Lines() ([]string, int) {
+	var (
+		buf      bytes.Buffer
+		location *types.Source
+		current  = -1
+	)
+	w := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	for i, ins := range dis.Instructions {
+		marker := "  "
+		if dis.InstructionPointer != "" && sameAddress(ins.Address, dis.InstructionPointer) {
+			marker, current = "=>", i
+		}
+		if ins.Location != nil {
+			location = ins.Location
+		}
+		var source string
+		if ins.Line != nil && location != nil {
+			name := location.Name
+			if name == nil && location.Path != nil {
+				base := filepath.Base(*location.Path)
+				name = &base
+			}
+			if name != nil {
+				source = fmt.Sprintf("%s:%d", *name, *ins.Line)
+			}
+		}
+		instruction := ins.Instruction
+		if ins.PresentationHint == "invalid" {
+			instruction = "??"
+		}
+		fmt.Fprintf(w, "%s %s\t%s\t%s\t%s\t%s\n", marker, ins.Address, ins.InstructionBytes, instruction, ins.Symbol, source)
+	}
+	w.Flush()
+
+	text := strings.TrimSuffix(buf.String(), "\n")
+	if text == "" {
+		return nil, current
+	}
+	lines := strings.Split(text, "\n")
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], " ")
+	}
+	return lines, current
+}
+
+// sameAddress reports whether two addresses are the same, even if they're
+// formatted differently, such as with leading zeros.
+func sameAddress(a, b string) bool {
+	x, errA := strconv.ParseUint(a, 0, 64)
+	y, errB := strconv.ParseUint(b, 0, 64)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return x == y
+}
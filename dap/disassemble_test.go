@@ -0,0 +1,32 @@
+package dap_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dradtke/debug-console/dap"
+	"github.com/dradtke/debug-console/types"
+)
+
+func TestDisassemblyLines(t *testing.T) {
+	name, line := "main.go", 10
+	dis := dap.Disassembly{
+		Instructions: []types.DisassembledInstruction{
+			{Address: "0x401000", InstructionBytes: "55", Instruction: "push rbp", Symbol: "main.main", Location: &types.Source{Name: &name}, Line: &line},
+			{Address: "0x401001", InstructionBytes: "48 89 e5", Instruction: "mov rbp, rsp"},
+		},
+		InstructionPointer: "0x0000000000401001",
+	}
+
+	lines, current := dis.Lines()
+	expected := []string{
+		"   0x401000  55        push rbp      main.main  main.go:10",
+		"=> 0x401001  48 89 e5  mov rbp, rsp",
+	}
+	if !reflect.DeepEqual(lines, expected) {
+		t.Errorf("unexpected lines:\n%q\nexpected:\n%q", lines, expected)
+	}
+	if current != 1 {
+		t.Errorf("unexpected current instruction: %d", current)
+	}
+}
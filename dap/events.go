@@ -5,6 +5,7 @@ import (
 	"log"
 
 	"github.com/dradtke/debug-console/types"
+	"github.com/dradtke/debug-console/util"
 )
 
 func (d *DAP) HandleEvent(event types.Event) {
@@ -32,6 +33,58 @@ func (d *DAP) HandleEvent(event types.Event) {
 			log.Printf("Error showing output: %s", err)
 		}
 
+	case "stopped":
+		var stopped types.StoppedEvent
+		if err := json.Unmarshal(event.Body, &stopped); err != nil {
+			log.Printf("Error parsing stopped event: %s", err)
+		} else {
+			d.Breakpoints.recordHits(stopped.HitBreakpointIds)
+		}
+		// Not every adapter sends thread events, so don't trust the cached
+		// thread list across stops.
+		d.Lock()
+		d.threads = nil
+		d.Unlock()
+		go func() {
+			defer util.Recover()
+			d.clearTemporaryBreakpoints()
+		}()
+
+	case "continued":
+		d.notifyConsole("ConsoleService.HandleContinued")
+
+	case "thread":
+		var thread types.ThreadEvent
+		if err := json.Unmarshal(event.Body, &thread); err != nil {
+			log.Printf("Error parsing thread event: %s", err)
+		} else {
+			d.handleThreadEvent(thread)
+		}
+
+	case "module":
+		var module types.ModuleEvent
+		if err := json.Unmarshal(event.Body, &module); err != nil {
+			log.Printf("Error parsing module event: %s", err)
+		} else {
+			d.applyModuleEvent(module)
+		}
+
+	case "loadedSource":
+		var source types.LoadedSourceEvent
+		if err := json.Unmarshal(event.Body, &source); err != nil {
+			log.Printf("Error parsing loadedSource event: %s", err)
+		} else {
+			d.applyLoadedSourceEvent(source)
+		}
+
+	case "breakpoint":
+		var breakpoint types.BreakpointEvent
+		if err := json.Unmarshal(event.Body, &breakpoint); err != nil {
+			log.Printf("Error parsing breakpoint event: %s", err)
+		} else {
+			d.Breakpoints.applyEvent(breakpoint)
+		}
+
 	case "terminated":
 		log.Print("Debug adapter terminated")
 		d.Stop()
@@ -0,0 +1,45 @@
+package dap
+
+import (
+	"errors"
+	"log"
+
+	"github.com/dradtke/debug-console/types"
+)
+
+// ExceptionInfo returns the details of the exception that stopped a thread.
+func (d *DAP) ExceptionInfo(threadID int) (types.ExceptionInfoResponse, error) {
+	d.RLock()
+	p, capabilities := d.Conn, d.Capabilities
+	d.RUnlock()
+	if capabilities == nil || !capabilities.SupportsExceptionInfoRequest {
+		return types.ExceptionInfoResponse{}, types.ErrUnsupported
+	}
+	if p == nil {
+		return types.ExceptionInfoResponse{}, errors.New("No process running")
+	}
+	return p.ExceptionInfo(types.ExceptionInfoArguments{ThreadID: threadID})
+}
+
+// showException records the exception that stopped a thread, and prints it
+// in the console.
+func (d *DAP) showException(threadID int) {
+	info, err := d.ExceptionInfo(threadID)
+	if errors.Is(err, types.ErrUnsupported) {
+		return
+	} else if err != nil {
+		log.Printf("Error getting exception info: %s", err)
+		return
+	}
+
+	d.Lock()
+	d.StoppedException = &info
+	d.Unlock()
+
+	if d.ConsoleClient == nil {
+		return
+	}
+	if err := d.ConsoleClient.Call("ConsoleService.ShowException", info, nil); err != nil {
+		log.Printf("Error invoking ConsoleService.ShowException: %s", err)
+	}
+}
@@ -0,0 +1,116 @@
+package dap
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dradtke/debug-console/types"
+)
+
+// Format controls how the debug adapter formats values and stack frames.
+type Format struct {
+	// Hex shows integers in hexadecimal.
+	Hex bool
+	// Module and ParameterTypes add the module and the types of the
+	// parameters to the names of stack frames.
+	Module, ParameterTypes bool
+}
+
+// ParseFormat parses format options, like "hex", "module" or "notypes", on top
+// of an existing format. "raw" turns off every option.
+func ParseFormat(f Format, options []string) (Format, error) {
+	for _, option := range options {
+		switch option {
+		case "hex", "x":
+			f.Hex = true
+		case "dec", "nohex":
+			f.Hex = false
+		case "module", "m":
+			f.Module = true
+		case "nomodule":
+			f.Module = false
+		case "types", "t":
+			f.ParameterTypes = true
+		case "notypes":
+			f.ParameterTypes = false
+		case "raw", "r":
+			f = Format{}
+		default:
+			return f, fmt.Errorf("Unknown format option: %s", option)
+		}
+	}
+	return f, nil
+}
+
+// String lists the format's options, or "raw" if none are set.
+func (f Format) String() string {
+	var options []string
+	if f.Hex {
+		options = append(options, "hex")
+	}
+	if f.Module {
+		options = append(options, "module")
+	}
+	if f.ParameterTypes {
+		options = append(options, "types")
+	}
+	if len(options) == 0 {
+		return "raw"
+	}
+	return strings.Join(options, " ")
+}
+
+// ValueFormat returns the format for evaluate and variables requests.
+func (f Format) ValueFormat() *types.ValueFormat {
+	return &types.ValueFormat{Hex: types.PtrBool(f.Hex)}
+}
+
+// StackFrameFormat returns the format for stackTrace requests. Parameters are
+// only shown if their types are asked for.
+func (f Format) StackFrameFormat() *types.StackFrameFormat {
+	return &types.StackFrameFormat{
+		ValueFormat:    *f.ValueFormat(),
+		Parameters:     types.PtrBool(f.ParameterTypes),
+		ParameterTypes: types.PtrBool(f.ParameterTypes),
+		Line:           types.PtrBool(true),
+		Module:         types.PtrBool(f.Module),
+	}
+}
+
+// SetFormat changes the session's format, and asks the editor to fetch values
+// and stacks again.
+func (d *DAP) SetFormat(f Format) {
+	d.Lock()
+	d.Format = f
+	d.Unlock()
+	d.invalidate("variables", "stacks")
+}
+
+// valueFormat returns the format to send with a request, which is the
+// session's format unless the request asks for its own. No format is sent if
+// the debug adapter doesn't support it.
+func (d *DAP) valueFormat(format *types.ValueFormat) *types.ValueFormat {
+	d.RLock()
+	defer d.RUnlock()
+	if d.Capabilities == nil || !d.Capabilities.SupportsValueFormattingOptions {
+		return nil
+	}
+	if format != nil {
+		return format
+	}
+	return d.Format.ValueFormat()
+}
+
+// stackFrameFormat is like valueFormat, but for stack traces, which always
+// ask for line numbers.
+func (d *DAP) stackFrameFormat(format *types.StackFrameFormat) *types.StackFrameFormat {
+	d.RLock()
+	defer d.RUnlock()
+	if d.Capabilities == nil || !d.Capabilities.SupportsValueFormattingOptions {
+		return &types.StackFrameFormat{Line: types.PtrBool(true)}
+	}
+	if format != nil {
+		return format
+	}
+	return d.Format.StackFrameFormat()
+}
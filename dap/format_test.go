@@ -0,0 +1,27 @@
+package dap_test
+
+import (
+	"testing"
+
+	"github.com/dradtke/debug-console/dap"
+)
+
+func TestParseFormat(t *testing.T) {
+	f, err := dap.ParseFormat(dap.Format{Module: true}, []string{"hex", "types", "nomodule"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if f != (dap.Format{Hex: true, ParameterTypes: true}) {
+		t.Errorf("unexpected format: %+v", f)
+	}
+	if f.String() != "hex types" {
+		t.Errorf("unexpected format string: %s", f)
+	}
+
+	if f, _ = dap.ParseFormat(f, []string{"raw"}); f != (dap.Format{}) {
+		t.Errorf("expected raw to clear the format, got: %+v", f)
+	}
+	if _, err := dap.ParseFormat(f, []string{"octal"}); err == nil {
+		t.Error("expected an error for an unknown option")
+	}
+}
@@ -0,0 +1,194 @@
+package dap
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/dradtke/debug-console/types"
+)
+
+// Memory is a block of memory read from the program.
+type Memory struct {
+	// Reference is the memory reference that was read, and Address is the
+	// address of the first byte of Data.
+	Reference string
+	Address   uint64
+	Data      []byte
+	// Unreadable is the number of bytes after Data that couldn't be read.
+	Unreadable int
+}
+
+// MemoryReference resolves target to a memory reference. Targets that look
+// like addresses are used as is, and anything else is evaluated in the
+// selected frame.
+func (d *DAP) MemoryReference(target string) (string, error) {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return "", errors.New("Must specify a memory reference or expression")
+	}
+	if isAddress(target) {
+		return target, nil
+	}
+
+	d.RLock()
+	p := d.Conn
+	d.RUnlock()
+	if p == nil {
+		return "", errors.New("No process running")
+	}
+	result, err := p.EvaluateResponse(types.EvaluateArguments{
+		Expression: target,
+		Context:    "watch",
+		FrameID:    d.FrameID(),
+	})
+	if err != nil {
+		return "", err
+	}
+	if result.MemoryReference != nil && *result.MemoryReference != "" {
+		return *result.MemoryReference, nil
+	}
+	// Pointers often don't carry a reference, but their value is an address.
+	if isAddress(result.Result) {
+		return result.Result, nil
+	}
+	return "", fmt.Errorf("No memory reference for %s", target)
+}
+
+// ReadMemory reads count bytes starting offset bytes from a memory reference.
+func (d *DAP) ReadMemory(ref string, offset, count int) (Memory, error) {
+	d.RLock()
+	p, capabilities := d.Conn, d.Capabilities
+	d.RUnlock()
+	if capabilities == nil || !capabilities.SupportsReadMemoryRequest {
+		return Memory{}, types.ErrUnsupported
+	}
+	if p == nil {
+		return Memory{}, errors.New("No process running")
+	}
+
+	resp, err := p.ReadMemory(types.ReadMemoryArguments{
+		MemoryReference: ref,
+		Offset:          offset,
+		Count:           count,
+	})
+	if err != nil {
+		return Memory{}, err
+	}
+	memory := Memory{Reference: ref, Unreadable: resp.UnreadableBytes}
+	if memory.Address, err = strconv.ParseUint(resp.Address, 0, 64); err != nil {
+		return memory, fmt.Errorf("Invalid memory address: %s", resp.Address)
+	}
+	if memory.Data, err = base64.StdEncoding.DecodeString(resp.Data); err != nil {
+		return memory, fmt.Errorf("Invalid memory data: %w", err)
+	}
+	return memory, nil
+}
+
+// WriteMemory writes data starting offset bytes from a memory reference, and
+// returns the number of bytes written.
+func (d *DAP) WriteMemory(ref string, offset int, data []byte) (int, error) {
+	d.RLock()
+	p, capabilities := d.Conn, d.Capabilities
+	d.RUnlock()
+	if capabilities == nil || !capabilities.SupportsWriteMemoryRequest {
+		return 0, types.ErrUnsupported
+	}
+	if p == nil {
+		return 0, errors.New("No process running")
+	}
+
+	resp, err := p.WriteMemory(types.WriteMemoryArguments{
+		MemoryReference: ref,
+		Offset:          offset,
+		Data:            base64.StdEncoding.EncodeToString(data),
+	})
+	if err != nil {
+		return 0, err
+	}
+	written := len(data)
+	if resp.BytesWritten != nil {
+		written = *resp.BytesWritten
+	}
+	if resp.Offset != nil {
+		offset = *resp.Offset
+	}
+
+	// Adapters aren't required to send a memory event for writes they were
+	// asked to make, so tell the editor directly.
+	d.notifyEditor("memory", types.MemoryEvent{MemoryReference: ref, Offset: offset, Count: written})
+	d.invalidate("variables")
+	return written, nil
+}
+
+// isAddress reports whether s is a hexadecimal address, like 0xc000012345.
+func isAddress(s string) bool {
+	if !strings.HasPrefix(s, "0x") {
+		return false
+	}
+	_, err := strconv.ParseUint(s, 0, 64)
+	return err == nil
+}
+
+// SplitCount splits the arguments of a command like "x <target> [count]" into
+// the target and the count. The last word is only taken as the count if it
+// isn't the only one, so that a bare number is still a target.
+func SplitCount(args []string, count int) (string, int) {
+	if len(args) > 1 {
+		if n, err := strconv.ParseUint(args[len(args)-1], 0, 32); err == nil && n > 0 {
+			return strings.Join(args[:len(args)-1], " "), int(n)
+		}
+	}
+	return strings.Join(args, " "), count
+}
+
+// Hexdump writes memory in the classic hex and ASCII layout, 16 bytes per
+// line.
+func Hexdump(w io.Writer, memory Memory) {
+	const width = 16
+	for i := 0; i < len(memory.Data); i += width {
+		line := memory.Data[i:]
+		if len(line) > width {
+			line = line[:width]
+		}
+		var hex, ascii strings.Builder
+		for j := 0; j < width; j++ {
+			if j == width/2 {
+				hex.WriteByte(' ')
+			}
+			if j >= len(line) {
+				hex.WriteString("   ")
+				continue
+			}
+			fmt.Fprintf(&hex, "%02x ", line[j])
+			if line[j] >= 0x20 && line[j] < 0x7f {
+				ascii.WriteByte(line[j])
+			} else {
+				ascii.WriteByte('.')
+			}
+		}
+		fmt.Fprintf(w, "0x%016x  %s |%s|\n", memory.Address+uint64(i), hex.String(), ascii.String())
+	}
+	if memory.Unreadable > 0 {
+		fmt.Fprintf(w, "0x%016x  (%d unreadable bytes)\n", memory.Address+uint64(len(memory.Data)), memory.Unreadable)
+	}
+}
+
+// notifyEditor sends a synthetic event to the editor, for changes that the
+// debug adapter doesn't report itself.
+func (d *DAP) notifyEditor(event string, v any) {
+	if d.EditorEventHandler == nil {
+		return
+	}
+	body, err := json.Marshal(v)
+	if err != nil {
+		log.Printf("Error creating %s event: %s", event, err)
+		return
+	}
+	d.EditorEventHandler(types.Event{Type: "event", Event: event, Body: body})
+}
@@ -0,0 +1,46 @@
+package dap_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dradtke/debug-console/dap"
+)
+
+func TestSplitCount(t *testing.T) {
+	for _, test := range []struct {
+		args   []string
+		target string
+		count  int
+	}{
+		{nil, "", 64},
+		{[]string{"16"}, "16", 64},
+		{[]string{"&x", "16"}, "&x", 16},
+		{[]string{"0xc000012345", "0x20"}, "0xc000012345", 32},
+		{[]string{"buf", "+", "1"}, "buf +", 1},
+		{[]string{"&x", "0"}, "&x 0", 64},
+		{[]string{"a", "+", "b"}, "a + b", 64},
+	} {
+		target, count := dap.SplitCount(test.args, 64)
+		if target != test.target || count != test.count {
+			t.Errorf("SplitCount(%q) = %q, %d; expected %q, %d", test.args, target, count, test.target, test.count)
+		}
+	}
+}
+
+func TestHexdump(t *testing.T) {
+	var b strings.Builder
+	dap.Hexdump(&b, dap.Memory{
+		Address:    0x1000,
+		Data:       []byte("hello, world!\x00\x01\x02\xffabc"),
+		Unreadable: 4,
+	})
+
+	expected := "" +
+		"0x0000000000001000  68 65 6c 6c 6f 2c 20 77  6f 72 6c 64 21 00 01 02  |hello, world!...|\n" +
+		"0x0000000000001010  ff 61 62 63                                       |.abc|\n" +
+		"0x0000000000001014  (4 unreadable bytes)\n"
+	if b.String() != expected {
+		t.Errorf("unexpected hexdump:\n%s\nexpected:\n%s", b.String(), expected)
+	}
+}
@@ -0,0 +1,126 @@
+package dap
+
+import (
+	"errors"
+
+	"github.com/dradtke/debug-console/types"
+)
+
+// Modules returns the modules loaded by the program. The list is fetched on
+// first use, and then kept up to date by module events.
+func (d *DAP) Modules() ([]types.Module, error) {
+	d.RLock()
+	p, capabilities, modules := d.Conn, d.Capabilities, d.modules
+	d.RUnlock()
+	if capabilities == nil || !capabilities.SupportsModulesRequest {
+		return nil, types.ErrUnsupported
+	}
+	if modules != nil {
+		return modules, nil
+	}
+	if p == nil {
+		return nil, errors.New("No process running")
+	}
+
+	modules, err := p.Modules()
+	if err != nil {
+		return nil, err
+	}
+	d.Lock()
+	if d.Conn == p {
+		d.modules = modules
+	}
+	d.Unlock()
+	return modules, nil
+}
+
+// LoadedSources returns the sources loaded by the program. Like Modules, the
+// list is kept up to date by loadedSource events.
+func (d *DAP) LoadedSources() ([]types.Source, error) {
+	d.RLock()
+	p, capabilities, sources := d.Conn, d.Capabilities, d.sources
+	d.RUnlock()
+	if capabilities == nil || !capabilities.SupportsLoadedSourcesRequest {
+		return nil, types.ErrUnsupported
+	}
+	if sources != nil {
+		return sources, nil
+	}
+	if p == nil {
+		return nil, errors.New("No process running")
+	}
+
+	sources, err := p.LoadedSources()
+	if err != nil {
+		return nil, err
+	}
+	d.Lock()
+	if d.Conn == p {
+		d.sources = sources
+	}
+	d.Unlock()
+	return sources, nil
+}
+
+// Source returns the content of a source that has a source reference, such as
+// generated code or a library without source files on disk.
+func (d *DAP) Source(source types.Source) (types.SourceResponse, error) {
+	d.RLock()
+	p := d.Conn
+	d.RUnlock()
+	if p == nil {
+		return types.SourceResponse{}, errors.New("No process running")
+	}
+	if source.Reference() == 0 {
+		return types.SourceResponse{}, errors.New("source has no reference")
+	}
+	return p.Source(types.SourceArguments{Source: &source, SourceReference: source.Reference()})
+}
+
+// applyModuleEvent updates the module list, if it has been fetched.
+func (d *DAP) applyModuleEvent(event types.ModuleEvent) {
+	d.Lock()
+	defer d.Unlock()
+	if d.modules == nil {
+		return
+	}
+	modules := make([]types.Module, 0, len(d.modules)+1)
+	for _, m := range d.modules {
+		if m.ID != event.Module.ID {
+			modules = append(modules, m)
+		}
+	}
+	if event.Reason != "removed" {
+		modules = append(modules, event.Module)
+	}
+	d.modules = modules
+}
+
+// applyLoadedSourceEvent updates the source list, if it has been fetched.
+func (d *DAP) applyLoadedSourceEvent(event types.LoadedSourceEvent) {
+	d.Lock()
+	defer d.Unlock()
+	if d.sources == nil {
+		return
+	}
+	sources := make([]types.Source, 0, len(d.sources)+1)
+	for _, s := range d.sources {
+		if !sameSource(s, event.Source) {
+			sources = append(sources, s)
+		}
+	}
+	if event.Reason != "removed" {
+		sources = append(sources, event.Source)
+	}
+	d.sources = sources
+}
+
+func sameSource(a, b types.Source) bool {
+	if a.Reference() > 0 || b.Reference() > 0 {
+		return a.Reference() == b.Reference()
+	}
+	if a.Path != nil || b.Path != nil {
+		return a.Path != nil && b.Path != nil && *a.Path == *b.Path
+	}
+	return a.Name != nil && b.Name != nil && *a.Name == *b.Name
+}
@@ -68,6 +68,10 @@ func (c *Conn) HandleErr() {
 	}
 }
 
+// HandleOut reads messages from the debug adapter until it exits. Responses and
+// events are both delivered from here, so event handlers must not send a
+// request and wait for its response; anything that does has to run in its own
+// goroutine.
 func (c *Conn) HandleOut() {
 	defer func() {
 		log.Println("Done reading stdout")
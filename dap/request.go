@@ -16,6 +16,10 @@ func (p *Conn) Initialize() (types.Response, error) {
 		ColumnsStartAt1:                     true,
 		SupportsRunInTerminalRequest:        true,
 		SupportsArgsCanBeInterpretedByShell: true,
+		SupportsVariableType:                true,
+		SupportsInvalidatedEvent:            true,
+		SupportsMemoryReferences:            true,
+		SupportsMemoryEvent:                 true,
 	}))
 }
 
@@ -24,77 +28,387 @@ func (p *Conn) ConfigurationDone() (types.Response, error) {
 }
 
 func (d *DAP) Continue() error {
-	d.Lock()
-	defer d.Unlock()
+	d.RLock()
+	p := d.Conn
 	args := types.ContinueArguments{}
 	if d.StoppedLocation != nil {
-		args.ThreadID = d.StoppedThreadID
+		args.ThreadID = d.selectedThread()
 	}
-	_, err := d.Conn.SendRequest(types.NewContinueRequest(args))
-	if err == nil {
-		d.StoppedLocation = nil
+	d.RUnlock()
+	if p == nil {
+		return errors.New("No process running")
 	}
-	return err
+	return d.resume(p, types.NewContinueRequest(args))
+}
+
+// Pause suspends the selected thread, or every thread if all is true or no
+// thread has been selected. Debug adapters without single thread execution
+// suspend every thread on any pause, so only one request is sent to them.
+func (d *DAP) Pause(all bool) error {
+	d.RLock()
+	p, threadID := d.Conn, d.selectedThread()
+	singleThread := d.Capabilities != nil && d.Capabilities.SupportsSingleThreadExecutionRequests
+	d.RUnlock()
+	if p == nil {
+		return errors.New("No process running")
+	}
+
+	if (!all || !singleThread) && threadID != 0 {
+		_, err := p.SendRequest(types.NewPauseRequest(types.PauseArguments{ThreadID: threadID}))
+		return err
+	}
+
+	threads, err := d.Threads()
+	if err != nil {
+		return err
+	}
+	if len(threads) == 0 {
+		return errors.New("No threads to pause")
+	}
+	if !singleThread {
+		_, err := p.SendRequest(types.NewPauseRequest(types.PauseArguments{ThreadID: threads[0].ID}))
+		return err
+	}
+	var errs []error
+	for _, thread := range threads {
+		if _, err := p.SendRequest(types.NewPauseRequest(types.PauseArguments{ThreadID: thread.ID})); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	// Threads that are already stopped can't be paused, so only fail if
+	// nothing could be paused.
+	if len(errs) == len(threads) {
+		return errs[0]
+	}
+	return nil
 }
 
 func (d *DAP) StepIn() error {
-	d.Lock()
-	defer d.Unlock()
-	_, err := d.Conn.SendRequest(types.NewStepInRequest())
-	if err == nil {
-		d.StoppedLocation = nil
+	return d.stepIn(nil)
+}
+
+// StepInto steps into a specific target returned by StepInTargets.
+func (d *DAP) StepInto(targetID int) error {
+	return d.stepIn(&targetID)
+}
+
+func (d *DAP) stepIn(targetID *int) error {
+	p, threadID, singleThread, err := d.steppingThread()
+	if err != nil {
+		return err
 	}
-	return err
+	return d.resume(p, types.NewStepInRequest(types.StepInArguments{
+		ThreadID:     threadID,
+		SingleThread: singleThread,
+		TargetID:     targetID,
+	}))
+}
+
+// StepInTargets returns the functions that can be stepped into from the
+// selected frame, which belongs to the thread that stepping resumes.
+func (d *DAP) StepInTargets() ([]types.StepInTarget, error) {
+	d.RLock()
+	p, capabilities := d.Conn, d.Capabilities
+	d.RUnlock()
+	if capabilities == nil || !capabilities.SupportsStepInTargetsRequest {
+		return nil, types.ErrUnsupported
+	}
+	frameID := d.FrameID()
+	if p == nil || frameID == 0 {
+		return nil, errors.New("not stopped")
+	}
+
+	resp, err := p.SendRequest(types.NewStepInTargetsRequest(types.StepInTargetsArguments{
+		FrameID: frameID,
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	var body types.StepInTargetsResponse
+	if err := json.Unmarshal(resp.Body, &body); err != nil {
+		return nil, fmt.Errorf("Error parsing stepInTargets response: %w", err)
+	}
+	return body.Targets, nil
 }
 
 func (d *DAP) StepOut() error {
-	d.Lock()
-	defer d.Unlock()
-	_, err := d.Conn.SendRequest(types.NewStepOutRequest())
-	if err == nil {
-		d.StoppedLocation = nil
+	p, threadID, singleThread, err := d.steppingThread()
+	if err != nil {
+		return err
 	}
-	return err
+	return d.resume(p, types.NewStepOutRequest(types.StepOutArguments{
+		ThreadID:     threadID,
+		SingleThread: singleThread,
+	}))
 }
 
-func (d *DAP) StepBack() error {
-	d.Lock()
-	defer d.Unlock()
-	_, err := d.Conn.SendRequest(types.NewStepBackRequest())
-	if err == nil {
-		d.StoppedLocation = nil
+// StepBack steps the selected thread backwards, which requires a debug
+// adapter that can record execution.
+func (d *DAP) StepBack(granularity string) error {
+	if !d.supportsStepBack() {
+		return types.ErrUnsupported
 	}
-	return err
+	p, threadID, singleThread, err := d.steppingThread()
+	if err != nil {
+		return err
+	}
+	return d.resume(p, types.NewStepBackRequest(types.StepBackArguments{
+		ThreadID:     threadID,
+		SingleThread: singleThread,
+		Granularity:  granularity,
+	}))
+}
+
+// ReverseContinue runs the selected thread backwards until a breakpoint, or
+// the start of the recording, is reached.
+func (d *DAP) ReverseContinue() error {
+	if !d.supportsStepBack() {
+		return types.ErrUnsupported
+	}
+	p, threadID, singleThread, err := d.steppingThread()
+	if err != nil {
+		return err
+	}
+	return d.resume(p, types.NewReverseContinueRequest(types.ReverseContinueArguments{
+		ThreadID:     threadID,
+		SingleThread: singleThread,
+	}))
+}
+
+func (d *DAP) supportsStepBack() bool {
+	d.RLock()
+	defer d.RUnlock()
+	return d.Capabilities != nil && d.Capabilities.SupportsStepBack
 }
 
 func (d *DAP) Next(granularity string) error {
-	d.Lock()
-	defer d.Unlock()
-	if d.StoppedThreadID == 0 {
-		return errors.New("no stopped thread!")
+	p, threadID, singleThread, err := d.steppingThread()
+	if err != nil {
+		return err
 	}
-	_, err := d.Conn.SendRequest(types.NewNextRequest(types.NextArguments{
-		ThreadID:            d.StoppedThreadID,
-		SteppingGranularity: granularity,
+	return d.resume(p, types.NewNextRequest(types.NextArguments{
+		ThreadID:     threadID,
+		SingleThread: singleThread,
+		Granularity:  granularity,
 	}))
-	if err == nil {
-		d.StoppedLocation = nil
+}
+
+// GotoTargets returns the locations on a line that the program counter can be
+// moved to.
+func (d *DAP) GotoTargets(path string, line int) ([]types.GotoTarget, error) {
+	d.RLock()
+	p, capabilities := d.Conn, d.Capabilities
+	d.RUnlock()
+	if capabilities == nil || !capabilities.SupportsGotoTargetsRequest {
+		return nil, types.ErrUnsupported
 	}
-	return err
+	if p == nil {
+		return nil, errors.New("No process running")
+	}
+
+	resp, err := p.SendRequest(types.NewGotoTargetsRequest(types.GotoTargetsArguments{
+		Source: d.Breakpoints.source(path),
+		Line:   line,
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	var body types.GotoTargetsResponse
+	if err := json.Unmarshal(resp.Body, &body); err != nil {
+		return nil, fmt.Errorf("Error parsing gotoTargets response: %w", err)
+	}
+	return body.Targets, nil
+}
+
+// Goto moves the selected thread's program counter to a target returned by
+// GotoTargets, without executing the code in between.
+func (d *DAP) Goto(targetID int) error {
+	p, threadID, _, err := d.steppingThread()
+	if err != nil {
+		return err
+	}
+	return d.resume(p, types.NewGotoRequest(types.GotoArguments{
+		ThreadID: threadID,
+		TargetID: targetID,
+	}))
 }
 
 func (p *Conn) Evaluate(args types.EvaluateArguments) (string, error) {
+	body, err := p.EvaluateResponse(args)
+	return body.Result, err
+}
+
+// EvaluateResponse is like Evaluate, but returns the whole response so that
+// structured results can be expanded.
+func (p *Conn) EvaluateResponse(args types.EvaluateArguments) (types.EvaluateResponse, error) {
 	resp, err := p.SendRequest(types.NewEvaluateRequest(args))
 	if err != nil {
-		return "", err
+		return types.EvaluateResponse{}, err
 	}
 
 	var body types.EvaluateResponse
 	if err := json.Unmarshal(resp.Body, &body); err != nil {
-		return "", fmt.Errorf("Error parsing evaluate response: %w", err)
+		return body, fmt.Errorf("Error parsing evaluate response: %w", err)
+	}
+	return body, nil
+}
+
+func (p *Conn) StackTrace(args types.StackTraceArguments) (types.StackTraceResponse, error) {
+	resp, err := p.SendRequest(types.NewStackTraceRequest(args))
+	if err != nil {
+		return types.StackTraceResponse{}, err
+	}
+
+	var body types.StackTraceResponse
+	if err := json.Unmarshal(resp.Body, &body); err != nil {
+		return body, fmt.Errorf("Error parsing stackTrace response: %w", err)
+	}
+	return body, nil
+}
+
+func (p *Conn) Scopes(args types.ScopesArguments) ([]types.Scope, error) {
+	resp, err := p.SendRequest(types.NewScopesRequest(args))
+	if err != nil {
+		return nil, err
+	}
+
+	var body types.ScopesResponse
+	if err := json.Unmarshal(resp.Body, &body); err != nil {
+		return nil, fmt.Errorf("Error parsing scopes response: %w", err)
+	}
+	return body.Scopes, nil
+}
+
+func (p *Conn) Variables(args types.VariablesArguments) ([]types.Variable, error) {
+	resp, err := p.SendRequest(types.NewVariablesRequest(args))
+	if err != nil {
+		return nil, err
+	}
+
+	var body types.VariablesResponse
+	if err := json.Unmarshal(resp.Body, &body); err != nil {
+		return nil, fmt.Errorf("Error parsing variables response: %w", err)
+	}
+	return body.Variables, nil
+}
+
+func (p *Conn) SetVariable(args types.SetVariableArguments) (types.SetVariableResponse, error) {
+	resp, err := p.SendRequest(types.NewSetVariableRequest(args))
+	if err != nil {
+		return types.SetVariableResponse{}, err
+	}
+
+	var body types.SetVariableResponse
+	if err := json.Unmarshal(resp.Body, &body); err != nil {
+		return body, fmt.Errorf("Error parsing setVariable response: %w", err)
+	}
+	return body, nil
+}
+
+func (p *Conn) SetExpression(args types.SetExpressionArguments) (types.SetVariableResponse, error) {
+	resp, err := p.SendRequest(types.NewSetExpressionRequest(args))
+	if err != nil {
+		return types.SetVariableResponse{}, err
+	}
+
+	var body types.SetVariableResponse
+	if err := json.Unmarshal(resp.Body, &body); err != nil {
+		return body, fmt.Errorf("Error parsing setExpression response: %w", err)
+	}
+	return body, nil
+}
+
+func (p *Conn) ExceptionInfo(args types.ExceptionInfoArguments) (types.ExceptionInfoResponse, error) {
+	resp, err := p.SendRequest(types.NewExceptionInfoRequest(args))
+	if err != nil {
+		return types.ExceptionInfoResponse{}, err
+	}
+
+	var body types.ExceptionInfoResponse
+	if err := json.Unmarshal(resp.Body, &body); err != nil {
+		return body, fmt.Errorf("Error parsing exceptionInfo response: %w", err)
+	}
+	return body, nil
+}
+
+func (p *Conn) Modules() ([]types.Module, error) {
+	resp, err := p.SendRequest(types.NewModulesRequest(types.ModulesArguments{}))
+	if err != nil {
+		return nil, err
+	}
+
+	var body types.ModulesResponse
+	if err := json.Unmarshal(resp.Body, &body); err != nil {
+		return nil, fmt.Errorf("Error parsing modules response: %w", err)
+	}
+	return body.Modules, nil
+}
+
+func (p *Conn) LoadedSources() ([]types.Source, error) {
+	resp, err := p.SendRequest(types.NewLoadedSourcesRequest())
+	if err != nil {
+		return nil, err
+	}
+
+	var body types.LoadedSourcesResponse
+	if err := json.Unmarshal(resp.Body, &body); err != nil {
+		return nil, fmt.Errorf("Error parsing loadedSources response: %w", err)
+	}
+	return body.Sources, nil
+}
+
+func (p *Conn) Source(args types.SourceArguments) (types.SourceResponse, error) {
+	resp, err := p.SendRequest(types.NewSourceRequest(args))
+	if err != nil {
+		return types.SourceResponse{}, err
+	}
+
+	var body types.SourceResponse
+	if err := json.Unmarshal(resp.Body, &body); err != nil {
+		return body, fmt.Errorf("Error parsing source response: %w", err)
+	}
+	return body, nil
+}
+
+func (p *Conn) ReadMemory(args types.ReadMemoryArguments) (types.ReadMemoryResponse, error) {
+	resp, err := p.SendRequest(types.NewReadMemoryRequest(args))
+	if err != nil {
+		return types.ReadMemoryResponse{}, err
+	}
+
+	var body types.ReadMemoryResponse
+	if err := json.Unmarshal(resp.Body, &body); err != nil {
+		return body, fmt.Errorf("Error parsing readMemory response: %w", err)
+	}
+	return body, nil
+}
+
+func (p *Conn) WriteMemory(args types.WriteMemoryArguments) (types.WriteMemoryResponse, error) {
+	resp, err := p.SendRequest(types.NewWriteMemoryRequest(args))
+	if err != nil {
+		return types.WriteMemoryResponse{}, err
+	}
+
+	var body types.WriteMemoryResponse
+	if err := json.Unmarshal(resp.Body, &body); err != nil {
+		return body, fmt.Errorf("Error parsing writeMemory response: %w", err)
+	}
+	return body, nil
+}
+
+func (p *Conn) Disassemble(args types.DisassembleArguments) (types.DisassembleResponse, error) {
+	resp, err := p.SendRequest(types.NewDisassembleRequest(args))
+	if err != nil {
+		return types.DisassembleResponse{}, err
 	}
 
-	return body.Result, nil
+	var body types.DisassembleResponse
+	if err := json.Unmarshal(resp.Body, &body); err != nil {
+		return body, fmt.Errorf("Error parsing disassemble response: %w", err)
+	}
+	return body, nil
 }
 
 func (p *Conn) Threads() ([]types.Thread, error) {
@@ -124,6 +438,32 @@ func (p *Conn) Completions(args types.CompletionsArguments) ([]types.CompletionI
 	return body.Targets, nil
 }
 
+func (p *Conn) SetBreakpoints(args types.SetBreakpointArguments) ([]types.Breakpoint, error) {
+	resp, err := p.SendRequest(types.NewSetBreakpointRequest(args))
+	if err != nil {
+		return nil, err
+	}
+
+	var body types.SetBreakpointsResponse
+	if err := json.Unmarshal(resp.Body, &body); err != nil {
+		return nil, fmt.Errorf("Error parsing setBreakpoints response: %w", err)
+	}
+	return body.Breakpoints, nil
+}
+
+func (p *Conn) BreakpointLocations(args types.BreakpointLocationsArguments) ([]types.BreakpointLocation, error) {
+	resp, err := p.SendRequest(types.NewBreakpointLocationsRequest(args))
+	if err != nil {
+		return nil, err
+	}
+
+	var body types.BreakpointLocationsResponse
+	if err := json.Unmarshal(resp.Body, &body); err != nil {
+		return nil, fmt.Errorf("Error parsing breakpointLocations response: %w", err)
+	}
+	return body.Breakpoints, nil
+}
+
 func (d *DAP) Terminate() error {
 	if !d.Capabilities.SupportTerminateDebuggee {
 		return types.ErrUnsupported
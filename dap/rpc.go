@@ -10,27 +10,47 @@ func (r DAPService) Continue(_ struct{}, _ *struct{}) error {
 	return r.d.Continue()
 }
 
+func (r DAPService) Pause(all bool, _ *struct{}) error {
+	return r.d.Pause(all)
+}
+
 func (r DAPService) StepIn(_ struct{}, _ *struct{}) error {
 	return r.d.StepIn()
 }
 
+func (r DAPService) StepInto(targetID int, _ *struct{}) error {
+	return r.d.StepInto(targetID)
+}
+
+func (r DAPService) StepInTargets(_ struct{}, result *[]types.StepInTarget) error {
+	v, err := r.d.StepInTargets()
+	if err != nil {
+		return err
+	}
+	*result = v
+	return nil
+}
+
 func (r DAPService) StepOut(_ struct{}, _ *struct{}) error {
 	return r.d.StepOut()
 }
 
-func (r DAPService) StepBack(_ struct{}, _ *struct{}) error {
-	return r.d.StepBack()
+func (r DAPService) StepBack(granularity string, _ *struct{}) error {
+	return r.d.StepBack(granularity)
+}
+
+func (r DAPService) ReverseContinue(_ struct{}, _ *struct{}) error {
+	return r.d.ReverseContinue()
 }
 
 func (r DAPService) Next(granularity string, _ *struct{}) error {
 	return r.d.Next(granularity)
 }
 
-func (r DAPService) Evaluate(args types.EvaluateArguments, result *string) error {
-	if r.d.StoppedLocation != nil {
-		args.FrameID = r.d.StoppedLocation.ID
-	}
-	v, err := r.d.Conn.Evaluate(args)
+func (r DAPService) Evaluate(args types.EvaluateArguments, result *types.EvaluateResponse) error {
+	args.FrameID = r.d.FrameID()
+	args.Format = r.d.valueFormat(args.Format)
+	v, err := r.d.Conn.EvaluateResponse(args)
 	if err != nil {
 		return err
 	}
@@ -39,7 +59,7 @@ func (r DAPService) Evaluate(args types.EvaluateArguments, result *string) error
 }
 
 func (r DAPService) Threads(_ struct{}, result *[]types.Thread) error {
-	v, err := r.d.Conn.Threads()
+	v, err := r.d.Threads()
 	if err != nil {
 		return err
 	}
@@ -47,6 +67,20 @@ func (r DAPService) Threads(_ struct{}, result *[]types.Thread) error {
 	return nil
 }
 
+func (r DAPService) TerminateThreads(threadIDs []int, _ *struct{}) error {
+	return r.d.TerminateThreads(threadIDs)
+}
+
+func (r DAPService) SelectThread(threadID int, _ *struct{}) error {
+	r.d.SelectThread(threadID)
+	return nil
+}
+
+func (r DAPService) SelectedThread(_ struct{}, result *int) error {
+	*result = r.d.SelectedThread()
+	return nil
+}
+
 func (r DAPService) Terminate(_ struct{}, _ *struct{}) error {
 	return r.d.Terminate()
 }
@@ -63,6 +97,11 @@ func (r DAPService) Capabilities(_ struct{}, capabilities *types.Capabilities) e
 }
 
 func (r DAPService) Completions(args types.CompletionsArguments, results *[]types.CompletionItem) error {
+	if args.FrameID == nil {
+		if frameID := r.d.FrameID(); frameID != 0 {
+			args.FrameID = &frameID
+		}
+	}
 	items, err := r.d.Conn.Completions(args)
 	if err != nil {
 		return err
@@ -70,3 +109,231 @@ func (r DAPService) Completions(args types.CompletionsArguments, results *[]type
 	*results = items
 	return nil
 }
+
+func (r DAPService) Breakpoints(_ struct{}, result *[]Breakpoint) error {
+	*result = r.d.Breakpoints.All()
+	return nil
+}
+
+// SourceBreakpoints returns the breakpoints in a single source.
+func (r DAPService) SourceBreakpoints(path string, result *[]Breakpoint) error {
+	*result = r.d.Breakpoints.Source(path)
+	return nil
+}
+
+func (r DAPService) AddBreakpoint(bp Breakpoint, result *Breakpoint) error {
+	v, err := r.d.AddBreakpoint(bp)
+	if err != nil {
+		return err
+	}
+	*result = v
+	return nil
+}
+
+func (r DAPService) RemoveBreakpoints(ids []int, _ *struct{}) error {
+	return r.d.RemoveBreakpoints(ids)
+}
+
+type EnableBreakpointsArgs struct {
+	IDs     []int
+	Enabled bool
+}
+
+func (r DAPService) EnableBreakpoints(args EnableBreakpointsArgs, _ *struct{}) error {
+	return r.d.SetBreakpointsEnabled(args.IDs, args.Enabled)
+}
+
+func (r DAPService) ClearBreakpoints(_ struct{}, _ *struct{}) error {
+	return r.d.ClearBreakpoints()
+}
+
+type StackTraceArgs struct {
+	// ThreadID defaults to the selected thread.
+	ThreadID   int
+	StartFrame int
+	Levels     int
+	// Format defaults to the session's format.
+	Format *Format
+}
+
+func (r DAPService) StackTrace(args StackTraceArgs, result *types.StackTraceResponse) error {
+	var format *types.StackFrameFormat
+	if args.Format != nil {
+		format = args.Format.StackFrameFormat()
+	}
+	v, err := r.d.stackTrace(args.ThreadID, args.StartFrame, args.Levels, format)
+	if err != nil {
+		return err
+	}
+	*result = v
+	return nil
+}
+
+func (r DAPService) RestartFrame(frameID int, _ *struct{}) error {
+	return r.d.RestartFrame(frameID)
+}
+
+// FrameSelection is a stack frame along with its position in the stack.
+type FrameSelection struct {
+	Index int
+	Frame types.StackFrame
+}
+
+func (r DAPService) SelectFrame(n int, result *FrameSelection) error {
+	v, err := r.d.SelectFrame(n)
+	if err != nil {
+		return err
+	}
+	*result = FrameSelection{Index: n, Frame: v}
+	return nil
+}
+
+func (r DAPService) MoveFrame(delta int, result *FrameSelection) error {
+	v, n, err := r.d.MoveFrame(delta)
+	if err != nil {
+		return err
+	}
+	*result = FrameSelection{Index: n, Frame: v}
+	return nil
+}
+
+func (r DAPService) SelectedFrame(_ struct{}, result *int) error {
+	r.d.RLock()
+	defer r.d.RUnlock()
+	*result = r.d.SelectedFrameIndex
+	return nil
+}
+
+func (r DAPService) Scopes(_ struct{}, result *[]types.Scope) error {
+	v, err := r.d.Scopes()
+	if err != nil {
+		return err
+	}
+	*result = v
+	return nil
+}
+
+func (r DAPService) Variables(args types.VariablesArguments, result *[]types.Variable) error {
+	v, err := r.d.Variables(args)
+	if err != nil {
+		return err
+	}
+	*result = v
+	return nil
+}
+
+type SetValueArgs struct {
+	Target string
+	Value  string
+}
+
+func (r DAPService) SetValue(args SetValueArgs, result *types.SetVariableResponse) error {
+	v, err := r.d.SetValue(args.Target, args.Value)
+	if err != nil {
+		return err
+	}
+	*result = v
+	return nil
+}
+
+func (r DAPService) Watches(_ struct{}, result *[]Watch) error {
+	*result = r.d.Watches.All()
+	return nil
+}
+
+func (r DAPService) AddWatch(expression string, result *Watch) error {
+	v, err := r.d.AddWatch(expression)
+	if err != nil {
+		return err
+	}
+	*result = v
+	return nil
+}
+
+func (r DAPService) RemoveWatches(ids []int, _ *struct{}) error {
+	return r.d.RemoveWatches(ids)
+}
+
+func (r DAPService) Modules(_ struct{}, result *[]types.Module) error {
+	v, err := r.d.Modules()
+	if err != nil {
+		return err
+	}
+	*result = v
+	return nil
+}
+
+func (r DAPService) LoadedSources(_ struct{}, result *[]types.Source) error {
+	v, err := r.d.LoadedSources()
+	if err != nil {
+		return err
+	}
+	*result = v
+	return nil
+}
+
+type ReadMemoryArgs struct {
+	// Target is a memory reference, or an expression that has one.
+	Target        string
+	Offset, Count int
+}
+
+func (r DAPService) ReadMemory(args ReadMemoryArgs, result *Memory) error {
+	ref, err := r.d.MemoryReference(args.Target)
+	if err != nil {
+		return err
+	}
+	v, err := r.d.ReadMemory(ref, args.Offset, args.Count)
+	if err != nil {
+		return err
+	}
+	*result = v
+	return nil
+}
+
+type WriteMemoryArgs struct {
+	Target string
+	Offset int
+	Data   []byte
+}
+
+func (r DAPService) WriteMemory(args WriteMemoryArgs, result *int) error {
+	ref, err := r.d.MemoryReference(args.Target)
+	if err != nil {
+		return err
+	}
+	v, err := r.d.WriteMemory(ref, args.Offset, args.Data)
+	if err != nil {
+		return err
+	}
+	*result = v
+	return nil
+}
+
+type DisassembleArgs struct {
+	// Target is a memory reference, or an expression that has one. If it's
+	// empty, the selected frame's instruction pointer is used.
+	Target string
+	Count  int
+}
+
+func (r DAPService) Disassemble(args DisassembleArgs, result *Disassembly) error {
+	v, err := r.d.Disassemble(args.Target, args.Count)
+	if err != nil {
+		return err
+	}
+	*result = v
+	return nil
+}
+
+func (r DAPService) Format(_ struct{}, result *Format) error {
+	r.d.RLock()
+	*result = r.d.Format
+	r.d.RUnlock()
+	return nil
+}
+
+func (r DAPService) SetFormat(f Format, _ *struct{}) error {
+	r.d.SetFormat(f)
+	return nil
+}
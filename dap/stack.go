@@ -0,0 +1,106 @@
+package dap
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dradtke/debug-console/types"
+)
+
+// StackTrace returns up to levels frames of a thread's stack, starting at
+// startFrame. A threadID of 0 means the selected thread, and levels of 0 means
+// all remaining frames.
+func (d *DAP) StackTrace(threadID, startFrame, levels int) (types.StackTraceResponse, error) {
+	return d.stackTrace(threadID, startFrame, levels, nil)
+}
+
+// stackTrace is like StackTrace, but with a format that overrides the
+// session's.
+func (d *DAP) stackTrace(threadID, startFrame, levels int, format *types.StackFrameFormat) (types.StackTraceResponse, error) {
+	d.RLock()
+	p := d.Conn
+	if threadID == 0 {
+		threadID = d.selectedThread()
+	}
+	d.RUnlock()
+	if p == nil {
+		return types.StackTraceResponse{}, errors.New("No process running")
+	}
+	if threadID == 0 {
+		return types.StackTraceResponse{}, errors.New("no stopped thread!")
+	}
+
+	return p.StackTrace(types.StackTraceArguments{
+		ThreadID:   threadID,
+		StartFrame: startFrame,
+		Levels:     levels,
+		Format:     d.stackFrameFormat(format),
+	})
+}
+
+// RestartFrame restarts execution of a stack frame from its beginning.
+func (d *DAP) RestartFrame(frameID int) error {
+	d.RLock()
+	p, capabilities := d.Conn, d.Capabilities
+	d.RUnlock()
+	if capabilities == nil || !capabilities.SupportsRestartFrame {
+		return types.ErrUnsupported
+	}
+	if p == nil {
+		return errors.New("No process running")
+	}
+	return d.resume(p, types.NewRestartFrameRequest(types.RestartFrameArguments{
+		FrameID: frameID,
+	}))
+}
+
+// SelectFrame selects the nth frame of the selected thread's stack, counting
+// from the top.
+func (d *DAP) SelectFrame(n int) (types.StackFrame, error) {
+	if n < 0 {
+		return types.StackFrame{}, errors.New("Already at the top frame")
+	}
+	stack, err := d.StackTrace(0, n, 1)
+	if err != nil {
+		return types.StackFrame{}, err
+	}
+	if len(stack.StackFrames) == 0 {
+		if n == 0 {
+			return types.StackFrame{}, errors.New("No stack frames")
+		}
+		return types.StackFrame{}, fmt.Errorf("No frame %d", n)
+	}
+
+	frame := stack.StackFrames[0]
+	d.Lock()
+	d.SelectedFrame, d.SelectedFrameIndex = &frame, n
+	d.Unlock()
+	if d.EditorFrameHandler != nil {
+		d.EditorFrameHandler(frame)
+	}
+	return frame, nil
+}
+
+// MoveFrame selects the frame delta levels away from the selected one, where
+// a positive delta moves towards the callers.
+func (d *DAP) MoveFrame(delta int) (types.StackFrame, int, error) {
+	d.RLock()
+	n := d.SelectedFrameIndex + delta
+	d.RUnlock()
+	frame, err := d.SelectFrame(n)
+	return frame, n, err
+}
+
+// FrameID returns the id of the frame that expressions should be evaluated
+// in, or 0 if there isn't one.
+func (d *DAP) FrameID() int {
+	d.RLock()
+	defer d.RUnlock()
+	if d.SelectedFrame != nil {
+		return d.SelectedFrame.ID
+	}
+	if d.StoppedLocation != nil && d.selectedThread() == d.StoppedThreadID {
+		return d.StoppedLocation.ID
+	}
+	return 0
+}
@@ -0,0 +1,60 @@
+package dap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// ProjectDir returns the directory used to persist state for the project in
+// the current working directory. A .debug-console directory in the project
+// takes precedence; otherwise a per-project directory under stateDir is used.
+func ProjectDir(stateDir string) (string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("ProjectDir: %w", err)
+	}
+	local := filepath.Join(cwd, ".debug-console")
+	if info, err := os.Stat(local); err == nil && info.IsDir() {
+		return local, nil
+	}
+	if stateDir == "" {
+		return "", nil
+	}
+	return filepath.Join(stateDir, "projects", url.PathEscape(cwd)), nil
+}
+
+// loadJSON reads the JSON in filename into v. It does nothing if filename is
+// empty or doesn't exist yet.
+func loadJSON(filename string, v any) error {
+	if filename == "" {
+		return nil
+	}
+	b, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// saveJSON writes v to filename as JSON, creating its directory if necessary.
+// It does nothing if filename is empty.
+func saveJSON(filename string, v any) error {
+	if filename == "" {
+		return nil
+	}
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filename, b, 0644)
+}
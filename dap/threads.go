@@ -0,0 +1,187 @@
+package dap
+
+import (
+	"errors"
+	"log"
+
+	"github.com/dradtke/debug-console/types"
+	"github.com/dradtke/debug-console/util"
+)
+
+// Threads returns the debuggee's threads. The list is cached after the first
+// request, and kept up to date by thread events.
+func (d *DAP) Threads() ([]types.Thread, error) {
+	d.RLock()
+	p, threads := d.Conn, d.threads
+	d.RUnlock()
+	if threads != nil {
+		return threads, nil
+	}
+	if p == nil {
+		return nil, errors.New("No process running")
+	}
+	return d.refreshThreads(p)
+}
+
+func (d *DAP) refreshThreads(p *Conn) ([]types.Thread, error) {
+	threads, err := p.Threads()
+	if err != nil {
+		return nil, err
+	}
+	d.Lock()
+	if d.Conn == p {
+		d.threads = threads
+	}
+	d.Unlock()
+	return threads, nil
+}
+
+// handleThreadEvent updates the thread list after a thread starts or exits.
+func (d *DAP) handleThreadEvent(event types.ThreadEvent) {
+	d.Lock()
+	p := d.Conn
+	if event.Reason == "exited" {
+		for i, thread := range d.threads {
+			if thread.ID == event.ThreadID {
+				d.threads = append(d.threads[:i:i], d.threads[i+1:]...)
+				break
+			}
+		}
+		if d.SelectedThreadID == event.ThreadID {
+			d.SelectedThreadID = 0
+		}
+		if d.StoppedThreadID == event.ThreadID {
+			d.StoppedThreadID = 0
+		}
+	}
+	d.Unlock()
+
+	if event.Reason != "started" || p == nil {
+		return
+	}
+	// The event doesn't include the thread's name, so ask for the full list.
+	go func() {
+		defer util.Recover()
+		if _, err := d.refreshThreads(p); err != nil {
+			log.Printf("Error refreshing threads: %s", err)
+		}
+	}()
+}
+
+// TerminateThreads ends the given threads, leaving the rest of the session
+// running.
+func (d *DAP) TerminateThreads(threadIDs []int) error {
+	d.RLock()
+	p, capabilities := d.Conn, d.Capabilities
+	d.RUnlock()
+	if capabilities == nil || !capabilities.SupportsTerminateThreadsRequest {
+		return types.ErrUnsupported
+	}
+	if p == nil {
+		return errors.New("No process running")
+	}
+	// The exited events can arrive before the response, so the threads are
+	// recorded first.
+	d.Lock()
+	if d.terminatedThreads == nil {
+		d.terminatedThreads = make(map[int]bool)
+	}
+	for _, id := range threadIDs {
+		d.terminatedThreads[id] = true
+	}
+	d.Unlock()
+	_, err := p.SendRequest(types.NewTerminateThreadsRequest(types.TerminateThreadsArguments{
+		ThreadIDs: threadIDs,
+	}))
+	if err != nil {
+		d.Lock()
+		for _, id := range threadIDs {
+			delete(d.terminatedThreads, id)
+		}
+		d.Unlock()
+	}
+	return err
+}
+
+// ThreadTerminated reports whether a thread that exited was ended by
+// TerminateThreads, and forgets about it.
+func (d *DAP) ThreadTerminated(threadID int) bool {
+	d.Lock()
+	defer d.Unlock()
+	terminated := d.terminatedThreads[threadID]
+	delete(d.terminatedThreads, threadID)
+	return terminated
+}
+
+// SelectThread sets the thread that stepping requests apply to.
+func (d *DAP) SelectThread(threadID int) {
+	d.Lock()
+	defer d.Unlock()
+	if threadID != d.SelectedThreadID {
+		d.SelectedFrame, d.SelectedFrameIndex = nil, 0
+	}
+	d.SelectedThreadID = threadID
+}
+
+// SelectedThread returns the thread that stepping requests apply to.
+func (d *DAP) SelectedThread() int {
+	d.RLock()
+	defer d.RUnlock()
+	return d.selectedThread()
+}
+
+// selectedThread must be called with the lock held.
+func (d *DAP) selectedThread() int {
+	if d.SelectedThreadID != 0 {
+		return d.SelectedThreadID
+	}
+	return d.StoppedThreadID
+}
+
+// steppingThread returns the connection and thread that a stepping request
+// should use, and whether the request should only resume that thread.
+func (d *DAP) steppingThread() (*Conn, int, bool, error) {
+	d.RLock()
+	defer d.RUnlock()
+	if d.Conn == nil {
+		return nil, 0, false, errors.New("No process running")
+	}
+	threadID := d.selectedThread()
+	if threadID == 0 {
+		return nil, 0, false, errors.New("no stopped thread!")
+	}
+	singleThread := d.Capabilities != nil && d.Capabilities.SupportsSingleThreadExecutionRequests
+	return d.Conn, threadID, singleThread, nil
+}
+
+// resume sends a request that resumes execution. The stopped state is cleared
+// before sending it, since the next stop can arrive before the response does.
+func (d *DAP) resume(p *Conn, req types.Request) error {
+	d.Lock()
+	stoppedLocation, selectedFrame, selectedFrameIndex := d.StoppedLocation, d.SelectedFrame, d.SelectedFrameIndex
+	d.StoppedLocation, d.SelectedFrame, d.SelectedFrameIndex = nil, nil, 0
+	d.StoppedException = nil
+	d.Unlock()
+	d.notifyConsole("ConsoleService.HandleContinued")
+
+	if _, err := p.SendRequest(req); err != nil {
+		d.Lock()
+		if d.StoppedLocation == nil {
+			d.StoppedLocation = stoppedLocation
+			d.SelectedFrame, d.SelectedFrameIndex = selectedFrame, selectedFrameIndex
+		}
+		d.Unlock()
+		d.notifyConsole("ConsoleService.HandleStopped")
+		return err
+	}
+	return nil
+}
+
+func (d *DAP) notifyConsole(method string) {
+	if d.ConsoleClient == nil {
+		return
+	}
+	if err := d.ConsoleClient.Call(method, struct{}{}, nil); err != nil {
+		log.Printf("Error invoking %s: %s", method, err)
+	}
+}
@@ -0,0 +1,149 @@
+package dap
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dradtke/debug-console/types"
+)
+
+// Scopes returns the scopes of the selected frame.
+func (d *DAP) Scopes() ([]types.Scope, error) {
+	d.RLock()
+	p := d.Conn
+	d.RUnlock()
+	if p == nil {
+		return nil, errors.New("No process running")
+	}
+	frameID := d.FrameID()
+	if frameID == 0 {
+		return nil, errors.New("not stopped")
+	}
+	return p.Scopes(types.ScopesArguments{FrameID: frameID})
+}
+
+// Variables returns the children of a scope or variable. Variable references
+// are only valid while the program is stopped.
+func (d *DAP) Variables(args types.VariablesArguments) ([]types.Variable, error) {
+	d.RLock()
+	p := d.Conn
+	d.RUnlock()
+	if p == nil {
+		return nil, errors.New("No process running")
+	}
+	args.Format = d.valueFormat(args.Format)
+	return p.Variables(args)
+}
+
+// Hover evaluates an expression in the selected frame for display in the
+// editor. The "hover" context is used if the debug adapter supports it, since
+// it should avoid side effects.
+func (d *DAP) Hover(expression string) (types.EvaluateResponse, error) {
+	d.RLock()
+	p, capabilities := d.Conn, d.Capabilities
+	d.RUnlock()
+	if p == nil {
+		return types.EvaluateResponse{}, errors.New("No process running")
+	}
+	context := "watch"
+	if capabilities != nil && capabilities.SupportsEvaluateForHovers {
+		context = "hover"
+	}
+	return p.EvaluateResponse(types.EvaluateArguments{
+		Expression: expression,
+		Context:    context,
+		FrameID:    d.FrameID(),
+		Format:     d.valueFormat(nil),
+	})
+}
+
+// SetValue assigns a new value to a variable or expression in the selected
+// frame. setExpression is used if the debug adapter supports it, and otherwise
+// target is resolved to a variable for setVariable, where nested fields are
+// separated by dots.
+func (d *DAP) SetValue(target, value string) (types.SetVariableResponse, error) {
+	d.RLock()
+	p, capabilities := d.Conn, d.Capabilities
+	d.RUnlock()
+	if p == nil {
+		return types.SetVariableResponse{}, errors.New("No process running")
+	}
+	if capabilities == nil || (!capabilities.SupportsSetExpression && !capabilities.SupportsSetVariable) {
+		return types.SetVariableResponse{}, types.ErrUnsupported
+	}
+
+	var (
+		result types.SetVariableResponse
+		err    error
+	)
+	if capabilities.SupportsSetExpression {
+		result, err = p.SetExpression(types.SetExpressionArguments{
+			Expression: target,
+			Value:      value,
+			FrameID:    d.FrameID(),
+		})
+	} else {
+		var ref int
+		if ref, err = d.containerReference(p, target); err == nil {
+			path := strings.Split(target, ".")
+			result, err = p.SetVariable(types.SetVariableArguments{
+				VariablesReference: ref,
+				Name:               path[len(path)-1],
+				Value:              value,
+			})
+		}
+	}
+	if err != nil {
+		return result, err
+	}
+
+	// Other values may depend on the one that changed, so refresh everything.
+	d.invalidate("variables")
+	return result, nil
+}
+
+// containerReference finds the variables reference of the scope or variable
+// that contains target, which is a dot-separated path from a variable in one
+// of the selected frame's scopes.
+func (d *DAP) containerReference(p *Conn, target string) (int, error) {
+	scopes, err := d.Scopes()
+	if err != nil {
+		return 0, err
+	}
+	path := strings.Split(target, ".")
+
+	for _, scope := range scopes {
+		if scope.Expensive {
+			continue
+		}
+		ref := scope.VariablesReference
+		for i, name := range path {
+			variables, err := p.Variables(types.VariablesArguments{VariablesReference: ref})
+			if err != nil {
+				return 0, err
+			}
+			found, child := false, 0
+			for _, v := range variables {
+				if v.Name == name {
+					found, child = true, v.VariablesReference
+					break
+				}
+			}
+			if found && i == len(path)-1 {
+				return ref, nil
+			}
+			if !found || child == 0 {
+				break
+			}
+			ref = child
+		}
+	}
+	return 0, fmt.Errorf("Variable not found: %s", target)
+}
+
+// invalidate tells the editor that some state, such as variable values, needs
+// to be fetched again.
+func (d *DAP) invalidate(areas ...string) {
+	d.notifyEditor("invalidated", types.InvalidatedEvent{Areas: areas})
+}
@@ -0,0 +1,192 @@
+package dap
+
+import (
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/dradtke/debug-console/types"
+)
+
+// Watch is an expression that is evaluated every time the program stops.
+type Watch struct {
+	ID         int    `json:"id"`
+	Expression string `json:"expression"`
+
+	// Value and Error are the result of the latest evaluation, and Changed
+	// reports whether Value differs from the evaluation before it.
+	Value     string `json:"-"`
+	Error     string `json:"-"`
+	Changed   bool   `json:"-"`
+	evaluated bool
+}
+
+// WatchStore holds the watch expressions, and is persisted as JSON like the
+// BreakpointStore.
+type WatchStore struct {
+	mu       sync.Mutex
+	filename string
+	nextID   int
+	watches  []*Watch
+}
+
+// NewWatchStore creates a store that persists to filename. If filename is
+// empty, watches are kept in memory only.
+func NewWatchStore(filename string) *WatchStore {
+	return &WatchStore{filename: filename, nextID: 1}
+}
+
+// Load reads the store's file, if it exists.
+func (s *WatchStore) Load() error {
+	var watches []*Watch
+	if err := loadJSON(s.filename, &watches); err != nil {
+		return fmt.Errorf("WatchStore.Load: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.watches = watches
+	for _, w := range watches {
+		if w.ID >= s.nextID {
+			s.nextID = w.ID + 1
+		}
+	}
+	return nil
+}
+
+// save writes the store's file. It must be called with the lock held.
+func (s *WatchStore) save() error {
+	if err := saveJSON(s.filename, s.watches); err != nil {
+		return fmt.Errorf("WatchStore.save: %w", err)
+	}
+	return nil
+}
+
+// Add creates a new watch and returns it with its ID assigned.
+func (s *WatchStore) Add(expression string) (Watch, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w := &Watch{ID: s.nextID, Expression: expression}
+	s.nextID++
+	s.watches = append(s.watches, w)
+	return *w, s.save()
+}
+
+// Remove deletes the watch with the given ID, and reports whether it existed.
+func (s *WatchStore) Remove(id int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, w := range s.watches {
+		if w.ID == id {
+			s.watches = append(s.watches[:i], s.watches[i+1:]...)
+			return true, s.save()
+		}
+	}
+	return false, nil
+}
+
+// All returns a copy of every watch.
+func (s *WatchStore) All() []Watch {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	watches := make([]Watch, len(s.watches))
+	for i, w := range s.watches {
+		watches[i] = *w
+	}
+	return watches
+}
+
+// record stores the result of evaluating a watch, and returns the updated
+// watch.
+func (s *WatchStore) record(id int, value string, err error) Watch {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, w := range s.watches {
+		if w.ID != id {
+			continue
+		}
+		var errMsg string
+		if err != nil {
+			value, errMsg = "", err.Error()
+		}
+		w.Changed = w.evaluated && (value != w.Value || errMsg != w.Error)
+		w.Value, w.Error, w.evaluated = value, errMsg, true
+		return *w
+	}
+	return Watch{}
+}
+
+// reset forgets the results of previous evaluations, so that nothing is
+// marked as changed in a new session.
+func (s *WatchStore) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, w := range s.watches {
+		w.Value, w.Error, w.Changed, w.evaluated = "", "", false, false
+	}
+}
+
+// AddWatch adds a watch expression, evaluating it right away if the program
+// is stopped.
+func (d *DAP) AddWatch(expression string) (Watch, error) {
+	w, err := d.Watches.Add(expression)
+	if err != nil {
+		return w, err
+	}
+	d.RLock()
+	p, stopped := d.Conn, d.StoppedLocation != nil
+	d.RUnlock()
+	if p != nil && stopped {
+		w = d.evaluateWatch(p, d.FrameID(), w)
+	}
+	return w, nil
+}
+
+// RemoveWatches deletes the watches with the given IDs.
+func (d *DAP) RemoveWatches(ids []int) error {
+	for _, id := range ids {
+		if ok, err := d.Watches.Remove(id); err != nil {
+			return err
+		} else if !ok {
+			return fmt.Errorf("no watch with id %d", id)
+		}
+	}
+	return nil
+}
+
+// EvaluateWatches evaluates every watch in the selected frame, and returns
+// the results.
+func (d *DAP) EvaluateWatches() []Watch {
+	d.RLock()
+	p := d.Conn
+	d.RUnlock()
+	if p == nil {
+		return d.Watches.All()
+	}
+	frameID := d.FrameID()
+	for _, w := range d.Watches.All() {
+		d.evaluateWatch(p, frameID, w)
+	}
+	return d.Watches.All()
+}
+
+func (d *DAP) evaluateWatch(p *Conn, frameID int, w Watch) Watch {
+	value, err := p.Evaluate(types.EvaluateArguments{
+		Expression: w.Expression,
+		Context:    "watch",
+		FrameID:    frameID,
+		Format:     d.valueFormat(nil),
+	})
+	return d.Watches.record(w.ID, value, err)
+}
+
+// showWatches evaluates the watches and prints them in the console.
+func (d *DAP) showWatches() {
+	watches := d.EvaluateWatches()
+	if len(watches) == 0 || d.ConsoleClient == nil {
+		return
+	}
+	if err := d.ConsoleClient.Call("ConsoleService.ShowWatches", watches, nil); err != nil {
+		log.Printf("Error invoking ConsoleService.ShowWatches: %s", err)
+	}
+}
@@ -17,22 +17,53 @@ function! s:Start(host) abort
 				\ 'rpc': v:true,
 				\ 'env': {
 					\ 'LOG_FILE': l:state.'/debug-console.log',
+					\ 'STATE_DIR': l:state.'/debug-console',
 					\ },
 				\ })
 endfunction
 
 call remote#host#Register('debug-console', 'x', function('s:Start'))
 
-sign define debug-console-breakpoint text=B
 sign define debug-console-current-location text=>
 
 " The end of this file will be updated when `make` is run with a new manifest.
 
 call remote#host#RegisterPlugin('debug-console', '0', [
+\ {'type': 'autocmd', 'name': 'BufReadPost', 'sync': 0, 'opts': {'eval': 'expand(''<afile>:p'')', 'pattern': '*'}},
+\ {'type': 'autocmd', 'name': 'BufWritePost', 'sync': 0, 'opts': {'eval': 'expand(''<afile>:p'')', 'pattern': '*'}},
 \ {'type': 'autocmd', 'name': 'VimLeave', 'sync': 0, 'opts': {'pattern': '*'}},
+\ {'type': 'command', 'name': 'BreakpointCondition', 'sync': 1, 'opts': {'eval': '{''Path'': expand(''%:p'')}', 'nargs': '*'}},
+\ {'type': 'command', 'name': 'BreakpointInfo', 'sync': 1, 'opts': {'eval': '{''Path'': expand(''%:p'')}'}},
 \ {'type': 'command', 'name': 'CurrentLocation', 'sync': 1, 'opts': {}},
+\ {'type': 'command', 'name': 'DebugDisassemble', 'sync': 1, 'opts': {'nargs': '*'}},
+\ {'type': 'command', 'name': 'DebugFormat', 'sync': 1, 'opts': {'nargs': '*'}},
+\ {'type': 'command', 'name': 'DebugHover', 'sync': 1, 'opts': {'count': '0', 'eval': '{''Expr'': expand(''<cexpr>'')}', 'nargs': '*'}},
+\ {'type': 'command', 'name': 'DebugJumpToCursor', 'sync': 1, 'opts': {'eval': '{''Path'': expand(''%:p'')}'}},
+\ {'type': 'command', 'name': 'DebugMemory', 'sync': 1, 'opts': {'nargs': '+'}},
+\ {'type': 'command', 'name': 'DebugNext', 'sync': 1, 'opts': {'nargs': '?'}},
+\ {'type': 'command', 'name': 'DebugPause', 'sync': 1, 'opts': {'bang': ''}},
+\ {'type': 'command', 'name': 'DebugRestartFrame', 'sync': 1, 'opts': {'eval': '{''Path'': expand(''%:p'')}'}},
+\ {'type': 'command', 'name': 'DebugReverseContinue', 'sync': 1, 'opts': {}},
 \ {'type': 'command', 'name': 'DebugRun', 'sync': 1, 'opts': {'eval': '{''Path'': expand(''%:p''), ''Filetype'': getbufvar(bufnr(''%''), ''&filetype'')}', 'nargs': '*'}},
-\ {'type': 'command', 'name': 'ToggleBreakpoint', 'sync': 1, 'opts': {}},
+\ {'type': 'command', 'name': 'DebugRunToCursor', 'sync': 1, 'opts': {'eval': '{''Path'': expand(''%:p'')}'}},
+\ {'type': 'command', 'name': 'DebugSelectThread', 'sync': 1, 'opts': {}},
+\ {'type': 'command', 'name': 'DebugSidebar', 'sync': 1, 'opts': {'nargs': '*'}},
+\ {'type': 'command', 'name': 'DebugSources', 'sync': 1, 'opts': {}},
+\ {'type': 'command', 'name': 'DebugStackToQuickfix', 'sync': 1, 'opts': {'bang': ''}},
+\ {'type': 'command', 'name': 'DebugStepBack', 'sync': 1, 'opts': {'nargs': '?'}},
+\ {'type': 'command', 'name': 'DebugStepInto', 'sync': 1, 'opts': {}},
+\ {'type': 'command', 'name': 'DebugThreadsToQuickfix', 'sync': 1, 'opts': {'bang': ''}},
+\ {'type': 'command', 'name': 'DebugUnwatch', 'sync': 1, 'opts': {'nargs': '*'}},
+\ {'type': 'command', 'name': 'DebugWatch', 'sync': 1, 'opts': {'nargs': '+'}},
+\ {'type': 'command', 'name': 'DebugWatches', 'sync': 1, 'opts': {}},
+\ {'type': 'command', 'name': 'Logpoint', 'sync': 1, 'opts': {'eval': '{''Path'': expand(''%:p'')}', 'nargs': '*'}},
+\ {'type': 'command', 'name': 'ToggleBreakpoint', 'sync': 1, 'opts': {'bang': '', 'eval': '{''Path'': expand(''%:p'')}'}},
+\ {'type': 'function', 'name': 'DebugConsoleHover', 'sync': 1, 'opts': {}},
 \ {'type': 'function', 'name': 'DebugConsoleLaunch', 'sync': 1, 'opts': {}},
+\ {'type': 'function', 'name': 'DebugConsoleNodeMemory', 'sync': 1, 'opts': {}},
+\ {'type': 'function', 'name': 'DebugConsolePageMemory', 'sync': 1, 'opts': {}},
+\ {'type': 'function', 'name': 'DebugConsolePicked', 'sync': 1, 'opts': {}},
 \ {'type': 'function', 'name': 'DebugConsoleRun', 'sync': 1, 'opts': {}},
+\ {'type': 'function', 'name': 'DebugConsoleSidebarAction', 'sync': 1, 'opts': {}},
+\ {'type': 'function', 'name': 'DebugConsoleToggleNode', 'sync': 1, 'opts': {}},
 \ ])
@@ -29,4 +29,16 @@ M.setup = function(plugin_path, config)
 	})
 end
 
+-- Evaluates the expression under the cursor, or the visual selection, and
+-- shows the result in a floating window.
+M.hover = function()
+	local mode = vim.fn.mode()
+	if mode == 'v' or mode == 'V' or mode == '\22' then
+		vim.api.nvim_feedkeys(vim.api.nvim_replace_termcodes('<Esc>', true, false, true), 'nx', false)
+		vim.cmd "'<,'>DebugHover"
+	else
+		vim.cmd 'DebugHover'
+	end
+end
+
 return M
@@ -0,0 +1,334 @@
+package nvim
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/dradtke/debug-console/dap"
+	"github.com/dradtke/debug-console/types"
+	"github.com/dradtke/debug-console/util"
+	"github.com/neovim/go-client/nvim"
+)
+
+// renderedLines records the line that each breakpoint's extmark was placed on,
+// by buffer and breakpoint ID. Comparing against it tells edits to the buffer
+// apart from lines chosen by the debug adapter.
+var (
+	renderedLines   = make(map[nvim.Buffer]map[int]int)
+	renderedLinesMu sync.Mutex
+)
+
+// loadedBuffer returns the buffer for path, if it is loaded.
+func loadedBuffer(v *nvim.Nvim, path string) (nvim.Buffer, bool, error) {
+	var loaded bool
+	if err := v.Call("bufloaded", &loaded, path); err != nil {
+		return 0, false, fmt.Errorf("loadedBuffer: %w", err)
+	}
+	if !loaded {
+		return 0, false, nil
+	}
+	var bufnr int
+	if err := v.Call("bufnr", &bufnr, path); err != nil {
+		return 0, false, fmt.Errorf("loadedBuffer: %w", err)
+	}
+	return nvim.Buffer(bufnr), true, nil
+}
+
+// RenderBreakpointSigns replaces the breakpoint extmarks in a source with the
+// current contents of the breakpoint store. Breakpoints are placed on the line
+// chosen by the debug adapter, if it has seen them.
+//
+// Each extmark's ID is the ID of its breakpoint, so that the breakpoint can
+// later be found again after edits have moved it. Any such edits are recorded
+// in the store first, so that they aren't lost.
+func RenderBreakpointSigns(v *nvim.Nvim, d *dap.DAP, path string) error {
+	buffer, ok, err := loadedBuffer(v, path)
+	if err != nil {
+		return fmt.Errorf("RenderBreakpointSigns: %w", err)
+	} else if !ok {
+		return nil
+	}
+
+	changed, err := RefreshBreakpointLines(v, d, path)
+	if err != nil {
+		return fmt.Errorf("RenderBreakpointSigns: %w", err)
+	}
+	if changed && d.HasSession() {
+		// The debug adapter still has the lines from before the edits.
+		go func() {
+			defer util.Recover()
+			if err := d.SyncBreakpoints(path); err != nil {
+				log.Printf("Error setting breakpoints: %s", err)
+			}
+		}()
+	}
+
+	ns, err := v.CreateNamespace(NamespaceBreakpoint)
+	if err != nil {
+		return fmt.Errorf("RenderBreakpointSigns: %w", err)
+	}
+	if err := v.ClearBufferNamespace(buffer, ns, 0, -1); err != nil {
+		return fmt.Errorf("RenderBreakpointSigns: %w", err)
+	}
+
+	rendered := make(map[int]int)
+	for _, bp := range d.Breakpoints.Source(path) {
+		opts := map[string]any{
+			"id":         bp.ID,
+			"sign_text":  "B",
+			"priority":   98,
+			"invalidate": true,
+		}
+		if bp.Temporary || bp.RunTo {
+			opts["sign_text"] = "T"
+		} else if bp.Disabled {
+			opts["sign_text"] = "b"
+			opts["sign_hl_group"] = "Comment"
+		} else if bp.Adapter != nil && !bp.Adapter.Verified {
+			opts["sign_text"] = "B?"
+			opts["sign_hl_group"] = "WarningMsg"
+		}
+		if _, err := v.SetBufferExtmark(buffer, ns, bp.EffectiveLine()-1, 0, opts); err != nil {
+			log.Printf("Error placing breakpoint %d at %s:%d: %s", bp.ID, path, bp.EffectiveLine(), err)
+			continue
+		}
+		rendered[bp.ID] = bp.EffectiveLine()
+	}
+
+	renderedLinesMu.Lock()
+	renderedLines[buffer] = rendered
+	renderedLinesMu.Unlock()
+	return nil
+}
+
+// RenderAllBreakpointSigns re-renders the breakpoint signs in every source that
+// has breakpoints.
+func RenderAllBreakpointSigns(v *nvim.Nvim, d *dap.DAP) {
+	for _, path := range d.Breakpoints.Paths() {
+		if err := RenderBreakpointSigns(v, d, path); err != nil {
+			log.Printf("Error rendering breakpoint signs: %s", err)
+		}
+	}
+}
+
+type breakpointMark struct {
+	ID      int  `msgpack:"id"`
+	Line    int  `msgpack:"line"`
+	Invalid bool `msgpack:"invalid"`
+}
+
+// RefreshBreakpointLines updates the breakpoint store with the current
+// position of each breakpoint's extmark, which moves as the buffer is edited.
+// Breakpoints whose lines have been deleted are removed. Extmarks that haven't
+// moved since they were rendered are left alone, since the store may have a
+// newer line from the debug adapter. It reports whether anything changed.
+func RefreshBreakpointLines(v *nvim.Nvim, d *dap.DAP, path string) (bool, error) {
+	buffer, ok, err := loadedBuffer(v, path)
+	if err != nil {
+		return false, fmt.Errorf("RefreshBreakpointLines: %w", err)
+	} else if !ok {
+		return false, nil
+	}
+
+	ns, err := v.CreateNamespace(NamespaceBreakpoint)
+	if err != nil {
+		return false, fmt.Errorf("RefreshBreakpointLines: %w", err)
+	}
+
+	var marks []breakpointMark
+	if err := v.ExecLua(`
+		local buffer, ns = ...
+		local marks = {}
+		for _, mark in ipairs(vim.api.nvim_buf_get_extmarks(buffer, ns, 0, -1, {details = true})) do
+			table.insert(marks, {id = mark[1], line = mark[2] + 1, invalid = mark[4].invalid == true})
+		end
+		return marks
+	`, &marks, buffer, ns); err != nil {
+		return false, fmt.Errorf("RefreshBreakpointLines: %w", err)
+	}
+
+	renderedLinesMu.Lock()
+	defer renderedLinesMu.Unlock()
+	rendered := renderedLines[buffer]
+
+	changed := false
+	for _, mark := range marks {
+		bp, ok := d.Breakpoints.Get(mark.ID)
+		line, seen := rendered[mark.ID]
+		if !ok || !seen || bp.Path != path {
+			continue
+		}
+		if mark.Invalid {
+			if _, err := d.Breakpoints.Remove(bp.ID); err != nil {
+				return changed, fmt.Errorf("RefreshBreakpointLines: %w", err)
+			}
+			delete(renderedLines[buffer], bp.ID)
+			Notify(v, fmt.Sprintf("Removed breakpoint at %s:%d because its line was deleted", path, bp.Line), nvim.LogWarnLevel)
+			changed = true
+		} else if mark.Line != line {
+			if err := d.Breakpoints.Move(bp.ID, mark.Line); err != nil {
+				return changed, fmt.Errorf("RefreshBreakpointLines: %w", err)
+			}
+			renderedLines[buffer][bp.ID] = mark.Line
+			changed = true
+		}
+	}
+	return changed, nil
+}
+
+// RestoreBreakpointSigns places stored breakpoint signs in newly-opened files.
+// Any extmarks left from before the file was read no longer say anything about
+// where its breakpoints are, so they aren't refreshed.
+func RestoreBreakpointSigns(v *nvim.Nvim, d *dap.DAP) func(string) {
+	return func(path string) {
+		defer util.Recover()
+		if buffer, ok, err := loadedBuffer(v, path); err == nil && ok {
+			renderedLinesMu.Lock()
+			delete(renderedLines, buffer)
+			renderedLinesMu.Unlock()
+		}
+		if err := RenderBreakpointSigns(v, d, path); err != nil {
+			log.Printf("Error restoring breakpoint signs: %s", err)
+		}
+	}
+}
+
+// SaveBreakpointLines records where a file's breakpoints ended up after it
+// was edited and saved.
+func SaveBreakpointLines(v *nvim.Nvim, d *dap.DAP) func(string) {
+	return func(path string) {
+		defer util.Recover()
+		changed, err := RefreshBreakpointLines(v, d, path)
+		if err != nil {
+			log.Printf("Error refreshing breakpoint lines: %s", err)
+			return
+		}
+		if changed {
+			BreakpointsChanged(v, d, path)
+		}
+	}
+}
+
+// breakpointSnapLines is how far around the requested line to look for a
+// valid breakpoint location.
+const breakpointSnapLines = 20
+
+// SnapBreakpoint returns the valid breakpoint location nearest to the given
+// line, in either direction. Ties go to the later line, which is where debug
+// adapters move breakpoints themselves. If the debug adapter can't say, the
+// line is returned unchanged.
+func SnapBreakpoint(d *dap.DAP, path string, line int) (int, int) {
+	start := line - breakpointSnapLines
+	if start < 1 {
+		start = 1
+	}
+	locations, err := d.BreakpointLocations(path, start, line+breakpointSnapLines)
+	if err != nil {
+		if !errors.Is(err, types.ErrUnsupported) {
+			log.Printf("Error getting breakpoint locations: %s", err)
+		}
+		return line, 0
+	}
+
+	var nearest *types.BreakpointLocation
+	for i, loc := range locations {
+		if nearest == nil || closerLocation(loc, *nearest, line) {
+			nearest = &locations[i]
+		}
+	}
+	if nearest == nil {
+		return line, 0
+	}
+	return nearest.Line, column(*nearest)
+}
+
+// closerLocation reports whether a is closer to line than b.
+func closerLocation(a, b types.BreakpointLocation, line int) bool {
+	if da, db := distance(a.Line, line), distance(b.Line, line); da != db {
+		return da < db
+	}
+	if a.Line != b.Line {
+		return a.Line > b.Line
+	}
+	return column(a) < column(b)
+}
+
+func column(loc types.BreakpointLocation) int {
+	if loc.Column == nil {
+		return 0
+	}
+	return *loc.Column
+}
+
+// ToggleBreakpointAt removes the breakpoint at the given location if there is
+// one, and otherwise adds one. A column of 0 matches any breakpoint on the line.
+func ToggleBreakpointAt(d *dap.DAP, path string, line, column int) error {
+	for _, bp := range d.Breakpoints.Source(path) {
+		if bp.EffectiveLine() == line && (column == 0 || bp.Column == column) {
+			_, err := d.Breakpoints.Remove(bp.ID)
+			return err
+		}
+	}
+	_, err := d.Breakpoints.Add(dap.Breakpoint{Path: path, Line: line, Column: column})
+	return err
+}
+
+// PickBreakpointLocation lets the user choose one of the valid breakpoint
+// locations on a line, and toggles a breakpoint there.
+func PickBreakpointLocation(v *nvim.Nvim, d *dap.DAP, path string, line int) error {
+	locations, err := d.BreakpointLocations(path, line, line)
+	if errors.Is(err, types.ErrUnsupported) {
+		Notify(v, "The debug adapter can't report breakpoint locations", nvim.LogWarnLevel)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("PickBreakpointLocation: %w", err)
+	}
+	if len(locations) == 0 {
+		Notify(v, "No valid breakpoint locations on this line", nvim.LogWarnLevel)
+		return nil
+	}
+
+	var text string
+	if err := v.Call("getline", &text, line); err != nil {
+		return fmt.Errorf("PickBreakpointLocation: %w", err)
+	}
+
+	items := make([]string, len(locations))
+	for i, loc := range locations {
+		col := column(loc)
+		if col > 0 && col <= len(text) {
+			items[i] = fmt.Sprintf("%d:%d: %s", loc.Line, col, strings.TrimSpace(text[col-1:]))
+		} else {
+			items[i] = fmt.Sprintf("%d: %s", loc.Line, strings.TrimSpace(text))
+		}
+	}
+
+	return Pick(v, "Breakpoint location", items, func(i int) error {
+		if err := ToggleBreakpointAt(d, path, locations[i].Line, column(locations[i])); err != nil {
+			return fmt.Errorf("PickBreakpointLocation: %w", err)
+		}
+		BreakpointsChanged(v, d, path)
+		return nil
+	})
+}
+
+// BreakpointsChanged pushes the breakpoints for a source to the debug adapter,
+// if one is running, which in turn updates its signs.
+func BreakpointsChanged(v *nvim.Nvim, d *dap.DAP, path string) {
+	if err := d.BreakpointsChanged(path); err != nil {
+		Notify(v, err.Error(), nvim.LogErrorLevel)
+	}
+}
+
+// HandleBreakpointsChanged updates the signs of a source whose breakpoints
+// were changed.
+func HandleBreakpointsChanged(v *nvim.Nvim, d *dap.DAP) func(string) {
+	return func(path string) {
+		if err := RenderBreakpointSigns(v, d, path); err != nil {
+			log.Printf("Error rendering breakpoint signs: %s", err)
+		}
+	}
+}
@@ -1,10 +1,13 @@
 package nvim
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/dradtke/debug-console/dap"
+	"github.com/dradtke/debug-console/types"
 	"github.com/dradtke/debug-console/util"
 	"github.com/neovim/go-client/nvim"
 	"github.com/neovim/go-client/nvim/plugin"
@@ -16,8 +19,35 @@ func RegisterCommands(p *plugin.Plugin, d *dap.DAP) {
 		NArgs: "*",
 		Eval:  "*",
 	}, DebugRun(d))
-	p.HandleCommand(&plugin.CommandOptions{Name: "ToggleBreakpoint"}, ToggleBreakpoint(d))
+	p.HandleCommand(&plugin.CommandOptions{Name: "ToggleBreakpoint", Bang: true, Eval: "*"}, ToggleBreakpoint(d))
+	p.HandleCommand(&plugin.CommandOptions{Name: "BreakpointCondition", NArgs: "*", Eval: "*"}, EditBreakpoint(d, func(bp *dap.Breakpoint, condition string) {
+		bp.Condition = condition
+	}))
+	p.HandleCommand(&plugin.CommandOptions{Name: "Logpoint", NArgs: "*", Eval: "*"}, EditBreakpoint(d, func(bp *dap.Breakpoint, message string) {
+		bp.LogMessage = message
+	}))
+	p.HandleCommand(&plugin.CommandOptions{Name: "BreakpointInfo", Eval: "*"}, BreakpointInfo(d))
 	p.HandleCommand(&plugin.CommandOptions{Name: "CurrentLocation"}, CurrentLocation(d))
+	p.HandleCommand(&plugin.CommandOptions{Name: "DebugPause", Bang: true}, Pause(d))
+	p.HandleCommand(&plugin.CommandOptions{Name: "DebugSelectThread"}, SelectThread(d))
+	p.HandleCommand(&plugin.CommandOptions{Name: "DebugStepInto"}, StepInto(d))
+	p.HandleCommand(&plugin.CommandOptions{Name: "DebugRunToCursor", Eval: "*"}, RunToCursor(d))
+	p.HandleCommand(&plugin.CommandOptions{Name: "DebugJumpToCursor", Eval: "*"}, JumpToCursor(d))
+	p.HandleCommand(&plugin.CommandOptions{Name: "DebugRestartFrame", Eval: "*"}, RestartFrame(d))
+	p.HandleCommand(&plugin.CommandOptions{Name: "DebugNext", NArgs: "?"}, Next(d))
+	p.HandleCommand(&plugin.CommandOptions{Name: "DebugStepBack", NArgs: "?"}, StepBack(d))
+	p.HandleCommand(&plugin.CommandOptions{Name: "DebugReverseContinue"}, ReverseContinue(d))
+	p.HandleCommand(&plugin.CommandOptions{Name: "DebugSidebar", NArgs: "*"}, ToggleSidebar(d))
+	p.HandleCommand(&plugin.CommandOptions{Name: "DebugSources"}, PickSource(d))
+	p.HandleCommand(&plugin.CommandOptions{Name: "DebugStackToQuickfix", Bang: true}, StackToQuickfix(d))
+	p.HandleCommand(&plugin.CommandOptions{Name: "DebugThreadsToQuickfix", Bang: true}, ThreadsToQuickfix(d))
+	p.HandleCommand(&plugin.CommandOptions{Name: "DebugHover", NArgs: "*", Count: "0", Eval: "*"}, Hover(d))
+	p.HandleCommand(&plugin.CommandOptions{Name: "DebugWatch", NArgs: "+"}, AddWatch(d))
+	p.HandleCommand(&plugin.CommandOptions{Name: "DebugUnwatch", NArgs: "*"}, RemoveWatch(d))
+	p.HandleCommand(&plugin.CommandOptions{Name: "DebugWatches"}, ListWatches(d))
+	p.HandleCommand(&plugin.CommandOptions{Name: "DebugMemory", NArgs: "+"}, ShowMemory(d))
+	p.HandleCommand(&plugin.CommandOptions{Name: "DebugDisassemble", NArgs: "*"}, ShowDisassembly(d))
+	p.HandleCommand(&plugin.CommandOptions{Name: "DebugFormat", NArgs: "*"}, SetFormat(d))
 	//p.HandleCommand(&plugin.CommandOptions{Name: "DebugConsoleTest"}, Test)
 }
 
@@ -26,9 +56,10 @@ func RegisterCommands(p *plugin.Plugin, d *dap.DAP) {
 //	return nil
 //}
 
-func OnDapExit(v *nvim.Nvim) func() {
+func OnDapExit(v *nvim.Nvim, d *dap.DAP) func() {
 	return func() {
 		RemoveAllSigns(v, SignGroupCurrentLocation)
+		RenderAllBreakpointSigns(v, d)
 	}
 }
 
@@ -54,40 +85,439 @@ func DebugRun(d *dap.DAP) any {
 	}
 }
 
+// ToggleBreakpoint toggles a breakpoint on the current line. If the debug
+// adapter can report valid breakpoint locations, new breakpoints are snapped to
+// the nearest one. With a bang, the user picks among the locations on the
+// current line, which allows for multiple breakpoints on one line.
 func ToggleBreakpoint(d *dap.DAP) any {
-	return func(v *nvim.Nvim) error {
+	return func(v *nvim.Nvim, bang bool, eval *struct {
+		Path string `eval:"expand('%:p')"`
+	}) error {
+		if staleSourceBuffer(d, eval.Path) {
+			Notify(v, "This source is from a debug session that has ended", nvim.LogWarnLevel)
+			return nil
+		}
 		lineNum, err := GetLineNumber(v)
 		if err != nil {
 			return fmt.Errorf("ToggleBreakpoint: %w", err)
 		}
-
-		sign, err := GetSignAt(v, SignGroupBreakpoint, "%", lineNum)
-		if err != nil {
+		if _, err := RefreshBreakpointLines(v, d, eval.Path); err != nil {
 			return fmt.Errorf("ToggleBreakpoint: %w", err)
 		}
 
-		if sign.Exists {
-			if err := RemoveSign(v, sign); err != nil {
+		if bang {
+			return PickBreakpointLocation(v, d, eval.Path, lineNum)
+		}
+
+		if bp, ok := d.Breakpoints.At(eval.Path, lineNum); ok {
+			if _, err := d.Breakpoints.Remove(bp.ID); err != nil {
 				return fmt.Errorf("ToggleBreakpoint: %w", err)
 			}
 		} else {
-			if err := PlaceSign(v, SignNameBreakpoint, sign, 98); err != nil {
+			line, column := SnapBreakpoint(d, eval.Path, lineNum)
+			if _, ok := d.Breakpoints.At(eval.Path, line); ok {
+				Notify(v, fmt.Sprintf("There is already a breakpoint on line %d", line), nvim.LogWarnLevel)
+				return nil
+			}
+			if _, err := d.Breakpoints.Add(dap.Breakpoint{Path: eval.Path, Line: line, Column: column}); err != nil {
 				return fmt.Errorf("ToggleBreakpoint: %w", err)
 			}
 		}
 
+		BreakpointsChanged(v, d, eval.Path)
+		return nil
+	}
+}
+
+// EditBreakpoint returns a command that modifies the breakpoint on the current
+// line using the command's arguments, creating the breakpoint if necessary.
+func EditBreakpoint(d *dap.DAP, edit func(bp *dap.Breakpoint, arg string)) any {
+	return func(v *nvim.Nvim, args []string, eval *struct {
+		Path string `eval:"expand('%:p')"`
+	}) error {
+		if staleSourceBuffer(d, eval.Path) {
+			Notify(v, "This source is from a debug session that has ended", nvim.LogWarnLevel)
+			return nil
+		}
+		lineNum, err := GetLineNumber(v)
+		if err != nil {
+			return fmt.Errorf("EditBreakpoint: %w", err)
+		}
+		if _, err := RefreshBreakpointLines(v, d, eval.Path); err != nil {
+			return fmt.Errorf("EditBreakpoint: %w", err)
+		}
+
+		arg := strings.Join(args, " ")
+		bp, ok := d.Breakpoints.At(eval.Path, lineNum)
+		if !ok {
+			bp = dap.Breakpoint{Path: eval.Path, Line: lineNum}
+			edit(&bp, arg)
+			_, err = d.Breakpoints.Add(bp)
+		} else {
+			_, err = d.Breakpoints.Update(bp.ID, func(bp *dap.Breakpoint) { edit(bp, arg) })
+		}
+		if err != nil {
+			return fmt.Errorf("EditBreakpoint: %w", err)
+		}
+
+		BreakpointsChanged(v, d, eval.Path)
+		return nil
+	}
+}
+
+func BreakpointInfo(d *dap.DAP) any {
+	return func(v *nvim.Nvim, eval *struct {
+		Path string `eval:"expand('%:p')"`
+	}) error {
+		lineNum, err := GetLineNumber(v)
+		if err != nil {
+			return fmt.Errorf("BreakpointInfo: %w", err)
+		}
+		if _, err := RefreshBreakpointLines(v, d, eval.Path); err != nil {
+			return fmt.Errorf("BreakpointInfo: %w", err)
+		}
+
+		bp, ok := d.Breakpoints.At(eval.Path, lineNum)
+		if !ok {
+			Notify(v, "No breakpoint on this line", nvim.LogWarnLevel)
+			return nil
+		}
+
+		var (
+			msg   string
+			level = nvim.LogInfoLevel
+		)
+		switch {
+		case bp.Adapter == nil:
+			msg = "Breakpoint not yet sent to a debug adapter"
+		case bp.Adapter.Verified:
+			msg = "Breakpoint verified"
+		default:
+			msg = "Breakpoint not verified"
+			level = nvim.LogWarnLevel
+		}
+		if bp.Adapter != nil && bp.Adapter.Message != nil {
+			msg += ": " + *bp.Adapter.Message
+		}
+		if bp.Condition != "" {
+			msg += "\nCondition: " + bp.Condition
+		}
+		if bp.LogMessage != "" {
+			msg += "\nLog message: " + bp.LogMessage
+		}
+		Notify(v, msg, level)
 		return nil
 	}
 }
 
 func CurrentLocation(d *dap.DAP) any {
 	return func(v *nvim.Nvim) error {
-		d.Lock()
-		defer d.Unlock()
-		if d.StoppedLocation == nil || d.StoppedLocation.Source == nil || d.StoppedLocation.Source.Path == nil {
+		d.RLock()
+		frame := d.SelectedFrame
+		if frame == nil {
+			frame = d.StoppedLocation
+		}
+		d.RUnlock()
+		if frame == nil || !hasSource(frame.Source) {
 			Notify(v, "No stopped location", nvim.LogWarnLevel)
 			return nil
 		}
-		return v.Command(fmt.Sprintf("keepalt edit +%d %s", d.StoppedLocation.Line, *d.StoppedLocation.Source.Path))
+		if err := openSource(v, d, *frame.Source, frame.Line); err != nil {
+			return fmt.Errorf("CurrentLocation: %w", err)
+		}
+		return nil
+	}
+}
+
+// Pause suspends the selected thread, or all threads with a bang.
+func Pause(d *dap.DAP) any {
+	return func(v *nvim.Nvim, bang bool) error {
+		if !d.HasSession() {
+			Notify(v, "No process running", nvim.LogWarnLevel)
+			return nil
+		}
+		if err := d.Pause(bang); err != nil {
+			return fmt.Errorf("Pause: %w", err)
+		}
+		return nil
+	}
+}
+
+// SelectThread picks the thread that stepping commands apply to. If the debug
+// adapter supports it, the picked thread can be terminated instead.
+func SelectThread(d *dap.DAP) any {
+	return func(v *nvim.Nvim) error {
+		if !d.HasSession() {
+			Notify(v, "No process running", nvim.LogWarnLevel)
+			return nil
+		}
+
+		threads, err := d.Threads()
+		if err != nil {
+			return fmt.Errorf("SelectThread: %w", err)
+		}
+
+		selected := d.SelectedThread()
+		items := make([]string, len(threads))
+		for i, thread := range threads {
+			marker := " "
+			if thread.ID == selected {
+				marker = "*"
+			}
+			items[i] = fmt.Sprintf("%s [%d] %s", marker, thread.ID, thread.Name)
+		}
+
+		return Pick(v, "Select thread", items, func(i int) error {
+			thread := threads[i]
+			sel := func() error {
+				d.SelectThread(thread.ID)
+				Notify(v, fmt.Sprintf("Selected thread %d: %s", thread.ID, thread.Name), nvim.LogInfoLevel)
+				RefreshSidebar(v, d)
+				return nil
+			}
+
+			d.RLock()
+			canTerminate := d.Capabilities != nil && d.Capabilities.SupportsTerminateThreadsRequest
+			d.RUnlock()
+			if !canTerminate {
+				return sel()
+			}
+
+			return Pick(v, fmt.Sprintf("Thread %d", thread.ID), []string{"Select", "Terminate"}, func(action int) error {
+				if action == 0 {
+					return sel()
+				}
+				if err := d.TerminateThreads([]int{thread.ID}); err != nil {
+					return fmt.Errorf("SelectThread: %w", err)
+				}
+				return nil
+			})
+		})
+	}
+}
+
+// SetFormat changes how values and stack frames are formatted for the rest of
+// the session, with options like "hex", "module" or "raw", and shows the
+// result.
+func SetFormat(d *dap.DAP) any {
+	return func(v *nvim.Nvim, args []string) error {
+		d.RLock()
+		f := d.Format
+		d.RUnlock()
+		if len(args) > 0 {
+			var err error
+			if f, err = dap.ParseFormat(f, args); err != nil {
+				return fmt.Errorf("SetFormat: %w", err)
+			}
+			d.SetFormat(f)
+		}
+		Notify(v, "Format: "+f.String(), nvim.LogInfoLevel)
+		return nil
+	}
+}
+
+// Next steps over the current statement, or line or instruction if given as a
+// granularity.
+func Next(d *dap.DAP) any {
+	return func(v *nvim.Nvim, args []string) error {
+		var granularity string
+		if len(args) > 0 {
+			granularity = args[0]
+		}
+		if err := d.Next(granularity); err != nil {
+			return fmt.Errorf("Next: %w", err)
+		}
+		return nil
+	}
+}
+
+// StepBack steps backwards, optionally with a granularity of "statement",
+// "line" or "instruction".
+func StepBack(d *dap.DAP) any {
+	return func(v *nvim.Nvim, args []string) error {
+		var granularity string
+		if len(args) > 0 {
+			granularity = args[0]
+		}
+		if err := d.StepBack(granularity); errors.Is(err, types.ErrUnsupported) {
+			Notify(v, "The debug adapter doesn't support reverse execution", nvim.LogWarnLevel)
+		} else if err != nil {
+			return fmt.Errorf("StepBack: %w", err)
+		}
+		return nil
+	}
+}
+
+// ReverseContinue runs backwards until the previous breakpoint.
+func ReverseContinue(d *dap.DAP) any {
+	return func(v *nvim.Nvim) error {
+		if err := d.ReverseContinue(); errors.Is(err, types.ErrUnsupported) {
+			Notify(v, "The debug adapter doesn't support reverse execution", nvim.LogWarnLevel)
+		} else if err != nil {
+			return fmt.Errorf("ReverseContinue: %w", err)
+		}
+		return nil
+	}
+}
+
+// StepInto lets the user pick which call on the current line to step into.
+func StepInto(d *dap.DAP) any {
+	return func(v *nvim.Nvim) error {
+		targets, err := d.StepInTargets()
+		if errors.Is(err, types.ErrUnsupported) {
+			Notify(v, "The debug adapter doesn't support step-in targets", nvim.LogWarnLevel)
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("StepInto: %w", err)
+		}
+		if len(targets) == 0 {
+			Notify(v, "No step-in targets", nvim.LogWarnLevel)
+			return nil
+		}
+
+		items := make([]string, len(targets))
+		for i, target := range targets {
+			items[i] = target.Label
+		}
+
+		return Pick(v, "Step into", items, func(i int) error {
+			return d.StepInto(targets[i].ID)
+		})
+	}
+}
+
+// RunToCursor continues until the cursor line is reached.
+func RunToCursor(d *dap.DAP) any {
+	return func(v *nvim.Nvim, eval *struct {
+		Path string `eval:"expand('%:p')"`
+	}) error {
+		lineNum, err := GetLineNumber(v)
+		if err != nil {
+			return fmt.Errorf("RunToCursor: %w", err)
+		}
+		if !d.HasSession() {
+			Notify(v, "No process running", nvim.LogWarnLevel)
+			return nil
+		}
+		if staleSourceBuffer(d, eval.Path) {
+			Notify(v, "This source is from a debug session that has ended", nvim.LogWarnLevel)
+			return nil
+		}
+		if _, err := RefreshBreakpointLines(v, d, eval.Path); err != nil {
+			return fmt.Errorf("RunToCursor: %w", err)
+		}
+		if err := d.RunTo(eval.Path, lineNum); err != nil {
+			return fmt.Errorf("RunToCursor: %w", err)
+		}
+		return nil
+	}
+}
+
+// JumpToCursor moves the program counter to the cursor line, after asking for
+// confirmation since the code in between won't be executed.
+func JumpToCursor(d *dap.DAP) any {
+	return func(v *nvim.Nvim, eval *struct {
+		Path string `eval:"expand('%:p')"`
+	}) error {
+		lineNum, err := GetLineNumber(v)
+		if err != nil {
+			return fmt.Errorf("JumpToCursor: %w", err)
+		}
+
+		targets, err := d.GotoTargets(eval.Path, lineNum)
+		if errors.Is(err, types.ErrUnsupported) {
+			Notify(v, "The debug adapter doesn't support jumping", nvim.LogWarnLevel)
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("JumpToCursor: %w", err)
+		}
+		if len(targets) == 0 {
+			Notify(v, "Can't jump to this line", nvim.LogWarnLevel)
+			return nil
+		}
+
+		jump := func(i int) error {
+			var choice int
+			if err := v.Call("confirm", &choice, fmt.Sprintf("Jump to %s? Code in between will be skipped.", targets[i].Label), "&Yes\n&No", 2); err != nil {
+				return fmt.Errorf("JumpToCursor: %w", err)
+			}
+			if choice != 1 {
+				return nil
+			}
+			return d.Goto(targets[i].ID)
+		}
+
+		if len(targets) == 1 {
+			return jump(0)
+		}
+
+		items := make([]string, len(targets))
+		for i, target := range targets {
+			items[i] = target.Label
+		}
+		return Pick(v, "Jump to", items, jump)
+	}
+}
+
+// RestartFrame restarts the stack frame that the cursor is in. Since frames
+// don't report where their function begins, any frame in the current file
+// could be the one; if there is more than one, such as with recursion, the
+// user picks.
+func RestartFrame(d *dap.DAP) any {
+	return func(v *nvim.Nvim, eval *struct {
+		Path string `eval:"expand('%:p')"`
+	}) error {
+		if !d.HasSession() {
+			Notify(v, "No process running", nvim.LogWarnLevel)
+			return nil
+		}
+
+		stack, err := d.StackTrace(0, 0, 0)
+		if err != nil {
+			return fmt.Errorf("RestartFrame: %w", err)
+		}
+
+		var (
+			frames []types.StackFrame
+			items  []string
+		)
+		for i, f := range stack.StackFrames {
+			if f.Source == nil || f.Source.Path == nil || *f.Source.Path != eval.Path {
+				continue
+			}
+			frames = append(frames, f)
+			items = append(items, fmt.Sprintf("#%d %s:%d", i, f.Name, f.Line))
+		}
+
+		restart := func(i int) error {
+			frame := frames[i]
+			if frame.CanRestart != nil && !*frame.CanRestart {
+				Notify(v, fmt.Sprintf("Frame %s can't be restarted", frame.Name), nvim.LogWarnLevel)
+				return nil
+			}
+			if err := d.RestartFrame(frame.ID); errors.Is(err, types.ErrUnsupported) {
+				Notify(v, "The debug adapter doesn't support restarting frames", nvim.LogWarnLevel)
+			} else if err != nil {
+				return fmt.Errorf("RestartFrame: %w", err)
+			}
+			return nil
+		}
+
+		switch len(frames) {
+		case 0:
+			Notify(v, "No stack frame in this file", nvim.LogWarnLevel)
+			return nil
+		case 1:
+			return restart(0)
+		default:
+			return Pick(v, "Restart frame", items, restart)
+		}
+	}
+}
+
+func distance(a, b int) int {
+	if a > b {
+		return a - b
 	}
+	return b - a
 }
@@ -0,0 +1,114 @@
+package nvim
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/dradtke/debug-console/dap"
+	"github.com/dradtke/debug-console/types"
+	"github.com/neovim/go-client/nvim"
+)
+
+// disassemblyCount is the number of instructions that the disassembly view
+// shows by default.
+const disassemblyCount = 40
+
+// disassembly tracks the disassembly buffer, and what it shows. An empty
+// target follows the selected frame's instruction pointer.
+var disassembly struct {
+	sync.Mutex
+	buf    nvim.Buffer
+	target string
+	count  int
+}
+
+// ShowDisassembly opens a buffer with the disassembly around an address or
+// expression, or the selected frame's instruction pointer if none is given.
+// If there's more than one argument, the last can be the number of
+// instructions to show.
+func ShowDisassembly(d *dap.DAP) any {
+	return func(v *nvim.Nvim, args []string) error {
+		target, count := dap.SplitCount(args, disassemblyCount)
+
+		var buf int
+		if err := v.ExecLua(`
+			local name = 'debug-console://disassembly'
+			local buf = vim.fn.bufnr(name)
+			if buf == -1 then
+				buf = vim.api.nvim_create_buf(false, true)
+				vim.api.nvim_buf_set_name(buf, name)
+				vim.bo[buf].bufhidden = 'hide'
+				vim.bo[buf].modifiable = false
+				vim.bo[buf].filetype = 'debug-console-disassembly'
+				vim.keymap.set('n', 'n', '<cmd>DebugNext instruction<cr>', {buffer = buf, nowait = true})
+				vim.keymap.set('n', 'q', '<cmd>close<cr>', {buffer = buf, nowait = true})
+			end
+			local win = vim.fn.bufwinid(buf)
+			if win == -1 then
+				vim.cmd 'belowright split'
+				vim.api.nvim_win_set_buf(0, buf)
+				vim.wo.number = false
+				vim.wo.relativenumber = false
+			else
+				vim.api.nvim_set_current_win(win)
+			end
+			return buf
+		`, &buf); err != nil {
+			return fmt.Errorf("ShowDisassembly: %w", err)
+		}
+
+		disassembly.Lock()
+		disassembly.buf = nvim.Buffer(buf)
+		disassembly.target, disassembly.count = target, count
+		disassembly.Unlock()
+		RefreshDisassembly(v, d)
+		return nil
+	}
+}
+
+// RefreshDisassembly disassembles again for the disassembly buffer, if it's
+// open, and moves the marker to the selected frame's instruction pointer.
+func RefreshDisassembly(v *nvim.Nvim, d *dap.DAP) {
+	disassembly.Lock()
+	buf, target, count := disassembly.buf, disassembly.target, disassembly.count
+	disassembly.Unlock()
+	if buf == 0 {
+		return
+	}
+	if valid, err := v.IsBufferValid(buf); err != nil || !valid {
+		disassembly.Lock()
+		disassembly.buf = 0
+		disassembly.Unlock()
+		return
+	}
+
+	result, err := d.Disassemble(target, count)
+	lines, current := result.Lines()
+	switch {
+	case errors.Is(err, types.ErrUnsupported):
+		lines = []string{"The debug adapter doesn't support disassembly"}
+	case err != nil:
+		lines = []string{err.Error()}
+	case len(lines) == 0:
+		lines = []string{"No instructions"}
+	}
+
+	if err := v.ExecLua(`
+		local buf, lines, current = ...
+		local ns = vim.api.nvim_create_namespace('debug-console-disassembly')
+		vim.bo[buf].modifiable = true
+		vim.api.nvim_buf_set_lines(buf, 0, -1, false, lines)
+		vim.bo[buf].modifiable = false
+		vim.api.nvim_buf_clear_namespace(buf, ns, 0, -1)
+		if current >= 0 then
+			vim.api.nvim_buf_set_extmark(buf, ns, current, 0, {line_hl_group = 'CursorLine'})
+			for _, win in ipairs(vim.fn.win_findbuf(buf)) do
+				vim.api.nvim_win_set_cursor(win, {current + 1, 0})
+			end
+		end
+	`, nil, int(buf), lines, current); err != nil {
+		log.Printf("Error rendering disassembly: %s", err)
+	}
+}
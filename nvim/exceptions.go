@@ -0,0 +1,67 @@
+package nvim
+
+import (
+	"log"
+	"strings"
+
+	"github.com/dradtke/debug-console/console"
+	"github.com/dradtke/debug-console/types"
+	"github.com/neovim/go-client/nvim"
+)
+
+const NamespaceException = "debug-console-exception"
+
+// ShowException notifies the user of the exception that stopped the program,
+// and marks the line that threw it with a diagnostic.
+func ShowException(v *nvim.Nvim, info types.ExceptionInfoResponse, frame types.StackFrame) {
+	var b strings.Builder
+	console.FormatException(&b, info)
+	msg := strings.TrimSuffix(b.String(), "\n")
+	Notify(v, msg, nvim.LogErrorLevel)
+
+	// Sources that the debug adapter provides have already been loaded to
+	// show the current location.
+	var (
+		path string
+		buf  = loadedSourceBuffer(frame.Source)
+	)
+	if frame.Source != nil && frame.Source.Path != nil {
+		path = *frame.Source.Path
+	}
+	if buf == 0 && (path == "" || frame.Source.Reference() > 0) {
+		return
+	}
+	summary := info.ExceptionID
+	if info.Details != nil && info.Details.Message != "" {
+		summary += ": " + info.Details.Message
+	} else if info.Description != "" {
+		summary += ": " + info.Description
+	}
+	column := frame.Column - 1
+	if column < 0 {
+		column = 0
+	}
+	if err := v.ExecLua(`
+		local ns, buf, path, line, col, message = ...
+		if buf == 0 then
+			buf = vim.fn.bufadd(path)
+			vim.fn.bufload(buf)
+		end
+		vim.diagnostic.set(vim.api.nvim_create_namespace(ns), buf, {{
+			lnum = line - 1,
+			col = col,
+			severity = vim.diagnostic.severity.ERROR,
+			message = message,
+			source = 'debug-console',
+		}})
+	`, nil, NamespaceException, int(buf), path, frame.Line, column, summary); err != nil {
+		log.Printf("Error setting exception diagnostic: %s", err)
+	}
+}
+
+// ClearException removes the diagnostic set by ShowException.
+func ClearException(v *nvim.Nvim) {
+	if err := v.ExecLua(`vim.diagnostic.reset(vim.api.nvim_create_namespace(...))`, nil, NamespaceException); err != nil {
+		log.Printf("Error clearing exception diagnostic: %s", err)
+	}
+}
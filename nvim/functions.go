@@ -16,6 +16,12 @@ func RegisterFunctions(p *plugin.Plugin, d *dap.DAP) {
 	// TODO: define a function that can be used to cancel a run + launch
 	p.HandleFunction(&plugin.FunctionOptions{Name: "DebugConsoleRun"}, Run(d))
 	p.HandleFunction(&plugin.FunctionOptions{Name: "DebugConsoleLaunch"}, Launch(d))
+	p.HandleFunction(&plugin.FunctionOptions{Name: "DebugConsolePicked"}, Picked)
+	p.HandleFunction(&plugin.FunctionOptions{Name: "DebugConsoleHover"}, HoverFunction(d))
+	p.HandleFunction(&plugin.FunctionOptions{Name: "DebugConsoleToggleNode"}, ToggleTreeNode(d))
+	p.HandleFunction(&plugin.FunctionOptions{Name: "DebugConsoleSidebarAction"}, SidebarAction(d))
+	p.HandleFunction(&plugin.FunctionOptions{Name: "DebugConsoleNodeMemory"}, ShowNodeMemory(d))
+	p.HandleFunction(&plugin.FunctionOptions{Name: "DebugConsolePageMemory"}, PageMemory(d))
 }
 
 func Run(d *dap.DAP) any {
@@ -23,7 +29,7 @@ func Run(d *dap.DAP) any {
 		if len(args) != 1 {
 			return errors.New("expected exactly one argument")
 		}
-		if _, err := d.Run(args[0], OnDapExit(v)); err != nil {
+		if _, err := d.Run(args[0], OnDapExit(v, d)); err != nil {
 			return err
 		}
 		d.RLock()
@@ -58,7 +64,7 @@ func Launch(d *dap.DAP) any {
 
 			log.Print("Sending the configuration")
 
-			if err := SendConfiguration(v, p); err != nil {
+			if err := SendConfiguration(v, d, p); err != nil {
 				log.Printf("Error sending configuration: %s", err)
 				return
 			}
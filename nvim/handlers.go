@@ -27,30 +27,101 @@ func HandleEvent(v *nvim.Nvim, d *dap.DAP) types.EventHandler {
 					log.Printf("Error handling stop: %s", err)
 					return
 				}
-				if stackFrame.Source.Name != nil {
+				if stackFrame == nil {
+					return
+				}
+				if stackFrame.Source != nil && stackFrame.Source.Name != nil {
 					msg := fmt.Sprintf("Stopped (%s) at %s:%d", stopped.Reason, *stackFrame.Source.Name, stackFrame.Line)
 					Notify(v, msg, nvim.LogInfoLevel)
 				}
-				if stackFrame.Source.Path != nil {
-					RemoveAllSigns(v, SignGroupCurrentLocation)
-					if err := PlaceSign(v, SignNameCurrentLocation, SignInfo{
-						Group:         SignGroupCurrentLocation,
-						BufferPattern: *stackFrame.Source.Path,
-						LineNumber:    stackFrame.Line,
-					}, 99); err != nil {
-						log.Printf("Error placing current location sign: %s", err)
-					}
+				ShowCurrentLocation(v, d, *stackFrame)
+				d.RLock()
+				exception := d.StoppedException
+				d.RUnlock()
+				ClearException(v)
+				if exception != nil {
+					ShowException(v, *exception, *stackFrame)
 				}
+				RefreshSidebar(v, d)
+				RefreshMemory(v, d)
+				RefreshDisassembly(v, d)
+			}()
+
+		case "breakpoint":
+			RenderAllBreakpointSigns(v, d)
+
+		case "thread":
+			var thread types.ThreadEvent
+			if err := json.Unmarshal(event.Body, &thread); err != nil {
+				log.Printf("Error parsing body: %s", err)
+			} else if thread.Reason == "exited" && d.ThreadTerminated(thread.ThreadID) {
+				// Other threads come and go too often to report.
+				Notify(v, fmt.Sprintf("Thread %d terminated", thread.ThreadID), nvim.LogInfoLevel)
+			}
+			go func() {
+				defer util.Recover()
+				RefreshSidebar(v, d, SidebarThreads)
 			}()
 
 		case "continued":
 			RemoveAllSigns(v, SignGroupCurrentLocation)
+			ClearException(v)
+			ClearSidebar(v, "Running...")
+
+		case "invalidated":
+			var invalidated types.InvalidatedEvent
+			if err := json.Unmarshal(event.Body, &invalidated); err != nil {
+				log.Printf("Error parsing body: %s", err)
+			}
+			views := []string{SidebarVariables}
+			for _, area := range invalidated.Areas {
+				if area == "stacks" || area == "all" {
+					views = append(views, SidebarStack)
+					break
+				}
+			}
+			go func() {
+				defer util.Recover()
+				RefreshSidebar(v, d, views...)
+			}()
+
+		case "memory":
+			go func() {
+				defer util.Recover()
+				RefreshMemory(v, d)
+			}()
 
 		case "terminated":
 			Notify(v, "Debug adapter terminated", nvim.LogInfoLevel)
 			RemoveAllSigns(v, SignGroupCurrentLocation)
+			ClearException(v)
+			ClearSidebar(v, "Not running")
+			forgetSources()
 		}
 	}
 }
 
+// ShowCurrentLocation moves the current location sign to a stack frame. Sources
+// that the debug adapter provides are loaded into a buffer first.
+func ShowCurrentLocation(v *nvim.Nvim, d *dap.DAP, frame types.StackFrame) {
+	if !hasSource(frame.Source) {
+		return
+	}
+	sign := SignInfo{Group: SignGroupCurrentLocation, LineNumber: frame.Line}
+	if frame.Source.Reference() > 0 {
+		buf, err := sourceBuffer(v, d, *frame.Source)
+		if err != nil {
+			log.Print(err)
+			return
+		}
+		sign.Buffer = buf
+	} else {
+		sign.BufferPattern = *frame.Source.Path
+	}
+	RemoveAllSigns(v, SignGroupCurrentLocation)
+	if err := PlaceSign(v, SignNameCurrentLocation, sign, 99); err != nil {
+		log.Printf("Error placing current location sign: %s", err)
+	}
+}
+
 // TODO: add a request handler for requests coming from the debug adapter
@@ -0,0 +1,149 @@
+package nvim
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/dradtke/debug-console/dap"
+	"github.com/neovim/go-client/nvim"
+)
+
+var (
+	// hoverWin is the floating window opened by the latest hover, if any.
+	hoverWin   nvim.Window
+	hoverBuf   nvim.Buffer
+	hoverWinMu sync.Mutex
+)
+
+// Hover evaluates the expression under the cursor and shows the result in a
+// floating window. When called with a range, such as from visual mode, the
+// last visual selection is evaluated instead. Running it again while the
+// window is open moves the cursor into it, where <CR> expands variables.
+func Hover(d *dap.DAP) any {
+	return func(v *nvim.Nvim, args []string, count int, eval *struct {
+		Expr string `eval:"expand('<cexpr>')"`
+	}) error {
+		if focusHover(v) {
+			return nil
+		}
+
+		expression := strings.Join(args, " ")
+		if expression == "" && count > 0 {
+			if err := v.ExecLua(`
+				local s, e = vim.fn.getpos("'<"), vim.fn.getpos("'>")
+				local last = math.min(e[3], #vim.fn.getline(e[2]))
+				return table.concat(vim.api.nvim_buf_get_text(0, s[2]-1, s[3]-1, e[2]-1, last, {}), "\n")
+			`, &expression); err != nil {
+				return fmt.Errorf("Hover: %w", err)
+			}
+		}
+		if expression == "" {
+			expression = eval.Expr
+		}
+		return showHover(v, d, expression)
+	}
+}
+
+// HoverFunction is like Hover, but callable from Lua with the expression to
+// evaluate.
+func HoverFunction(d *dap.DAP) any {
+	return func(v *nvim.Nvim, args []string) error {
+		if focusHover(v) {
+			return nil
+		}
+		return showHover(v, d, strings.Join(args, " "))
+	}
+}
+
+func showHover(v *nvim.Nvim, d *dap.DAP, expression string) error {
+	if strings.TrimSpace(expression) == "" {
+		Notify(v, "Nothing to evaluate", nvim.LogWarnLevel)
+		return nil
+	}
+	if !d.HasSession() {
+		Notify(v, "No process running", nvim.LogWarnLevel)
+		return nil
+	}
+
+	result, err := d.Hover(expression)
+	if err != nil {
+		Notify(v, err.Error(), nvim.LogWarnLevel)
+		return nil
+	}
+
+	root := &variableNode{
+		Name:    expression,
+		Type:    result.Type,
+		Value:   result.Result,
+		Ref:     result.VariablesReference,
+		Named:   result.NamedVariables,
+		Indexed: result.IndexedVariables,
+	}
+	if result.MemoryReference != nil {
+		root.MemoryRef = *result.MemoryReference
+	}
+	if root.Ref > 0 {
+		if root.children, err = loadChildren(d, root, 0); err != nil {
+			return fmt.Errorf("Hover: %w", err)
+		}
+		root.expanded = true
+	}
+
+	// Handles come back from Lua as plain integers.
+	var win struct {
+		Buf int `msgpack:"buf"`
+		Win int `msgpack:"win"`
+	}
+	if err := v.ExecLua(`
+		local buf = vim.api.nvim_create_buf(false, true)
+		vim.bo[buf].bufhidden = 'wipe'
+		vim.bo[buf].modifiable = false
+		local win = vim.api.nvim_open_win(buf, false, {
+			relative = 'cursor', row = 1, col = 0, width = 1, height = 1,
+			style = 'minimal', border = 'rounded',
+		})
+		vim.keymap.set('n', '<CR>', function()
+			vim.fn.DebugConsoleToggleNode(buf, vim.fn.line('.'))
+		end, {buffer = buf, nowait = true})
+		vim.keymap.set('n', 'm', function()
+			vim.fn.DebugConsoleNodeMemory(buf, vim.fn.line('.'))
+		end, {buffer = buf, nowait = true})
+		vim.keymap.set('n', 'q', '<cmd>close<cr>', {buffer = buf, nowait = true})
+		vim.api.nvim_create_autocmd({'CursorMoved', 'InsertEnter'}, {
+			buffer = vim.api.nvim_get_current_buf(),
+			once = true,
+			callback = function()
+				if vim.api.nvim_win_is_valid(win) and vim.api.nvim_get_current_win() ~= win then
+					vim.api.nvim_win_close(win, true)
+				end
+			end,
+		})
+		return {buf = buf, win = win}
+	`, &win); err != nil {
+		return fmt.Errorf("Hover: %w", err)
+	}
+
+	hoverWinMu.Lock()
+	oldBuf := hoverBuf
+	hoverWin, hoverBuf = nvim.Window(win.Win), nvim.Buffer(win.Buf)
+	hoverWinMu.Unlock()
+	forgetTree(oldBuf)
+
+	return ShowTree(v, nvim.Buffer(win.Buf), []*variableNode{root})
+}
+
+// focusHover moves the cursor into the hover window, and reports whether it
+// was open.
+func focusHover(v *nvim.Nvim) bool {
+	hoverWinMu.Lock()
+	win := hoverWin
+	hoverWinMu.Unlock()
+	if win == 0 {
+		return false
+	}
+	if valid, err := v.IsWindowValid(win); err != nil || !valid {
+		return false
+	}
+	return v.SetCurrentWindow(win) == nil
+}
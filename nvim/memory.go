@@ -0,0 +1,183 @@
+package nvim
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/dradtke/debug-console/dap"
+	"github.com/dradtke/debug-console/types"
+	"github.com/dradtke/debug-console/util"
+	"github.com/neovim/go-client/nvim"
+)
+
+// memoryPageSize is the number of bytes that a memory view shows by default.
+const memoryPageSize = 256
+
+// memoryView is what a memory buffer shows, relative to its reference.
+type memoryView struct {
+	ref           string
+	offset, count int
+}
+
+var (
+	memoryViews   = make(map[nvim.Buffer]*memoryView)
+	memoryViewsMu sync.Mutex
+)
+
+// ShowMemory opens a buffer with a hex dump of memory. The first argument is
+// a memory reference, or an expression that has one, and the last can be the
+// number of bytes to show.
+func ShowMemory(d *dap.DAP) any {
+	return func(v *nvim.Nvim, args []string) error {
+		target, count := dap.SplitCount(args, memoryPageSize)
+		ref, err := d.MemoryReference(target)
+		if err != nil {
+			return fmt.Errorf("ShowMemory: %w", err)
+		}
+		return openMemory(v, d, ref, count)
+	}
+}
+
+// ShowNodeMemory opens the memory view for the variable on a line of a tree
+// buffer. It is called from buffer-local mappings with the buffer and 1-based
+// line.
+func ShowNodeMemory(d *dap.DAP) any {
+	return func(v *nvim.Nvim, args []int) error {
+		if len(args) != 2 {
+			return errors.New("expected exactly two arguments")
+		}
+		treesMu.Lock()
+		t := trees[nvim.Buffer(args[0])]
+		treesMu.Unlock()
+		if t == nil {
+			return nil
+		}
+
+		t.mu.Lock()
+		var ref string
+		if line := args[1] - 1; line >= 0 && line < len(t.visible) {
+			ref = t.visible[line].MemoryRef
+		}
+		t.mu.Unlock()
+		if ref == "" {
+			Notify(v, "No memory reference", nvim.LogWarnLevel)
+			return nil
+		}
+		return openMemory(v, d, ref, memoryPageSize)
+	}
+}
+
+// PageMemory moves a memory view forward or backward by a number of pages.
+func PageMemory(d *dap.DAP) any {
+	return func(v *nvim.Nvim, args []int) error {
+		if len(args) != 2 {
+			return errors.New("expected exactly two arguments")
+		}
+		buf := nvim.Buffer(args[0])
+		memoryViewsMu.Lock()
+		view := memoryViews[buf]
+		if view != nil {
+			view.offset += args[1] * view.count
+		}
+		memoryViewsMu.Unlock()
+		if view == nil {
+			return nil
+		}
+
+		go func() {
+			defer util.Recover()
+			renderMemory(v, d, buf)
+		}()
+		return nil
+	}
+}
+
+func openMemory(v *nvim.Nvim, d *dap.DAP, ref string, count int) error {
+	var buf int
+	if err := v.ExecLua(`
+		local ref = ...
+		local name = 'debug-console://memory/' .. ref
+		local buf = vim.fn.bufnr(name)
+		if buf == -1 then
+			buf = vim.api.nvim_create_buf(false, true)
+			vim.api.nvim_buf_set_name(buf, name)
+			vim.bo[buf].bufhidden = 'wipe'
+			vim.bo[buf].modifiable = false
+			vim.bo[buf].filetype = 'debug-console-memory'
+			vim.keymap.set('n', ']', function()
+				vim.fn.DebugConsolePageMemory(buf, 1)
+			end, {buffer = buf, nowait = true})
+			vim.keymap.set('n', '[', function()
+				vim.fn.DebugConsolePageMemory(buf, -1)
+			end, {buffer = buf, nowait = true})
+			vim.keymap.set('n', 'q', '<cmd>close<cr>', {buffer = buf, nowait = true})
+		end
+		local win = vim.fn.bufwinid(buf)
+		if win == -1 then
+			vim.cmd 'belowright split'
+			vim.api.nvim_win_set_buf(0, buf)
+		else
+			vim.api.nvim_set_current_win(win)
+		end
+		return buf
+	`, &buf, ref); err != nil {
+		return fmt.Errorf("ShowMemory: %w", err)
+	}
+
+	memoryViewsMu.Lock()
+	memoryViews[nvim.Buffer(buf)] = &memoryView{ref: ref, count: count}
+	memoryViewsMu.Unlock()
+	renderMemory(v, d, nvim.Buffer(buf))
+	return nil
+}
+
+// RefreshMemory reads the memory of every open memory view again.
+func RefreshMemory(v *nvim.Nvim, d *dap.DAP) {
+	memoryViewsMu.Lock()
+	bufs := make([]nvim.Buffer, 0, len(memoryViews))
+	for buf := range memoryViews {
+		bufs = append(bufs, buf)
+	}
+	memoryViewsMu.Unlock()
+
+	for _, buf := range bufs {
+		renderMemory(v, d, buf)
+	}
+}
+
+func renderMemory(v *nvim.Nvim, d *dap.DAP, buf nvim.Buffer) {
+	if valid, err := v.IsBufferValid(buf); err != nil || !valid {
+		memoryViewsMu.Lock()
+		delete(memoryViews, buf)
+		memoryViewsMu.Unlock()
+		return
+	}
+	memoryViewsMu.Lock()
+	current := memoryViews[buf]
+	memoryViewsMu.Unlock()
+	if current == nil {
+		return
+	}
+	view := *current
+
+	lines := []string{fmt.Sprintf("%s%+d, %d bytes ([ and ] to page)", view.ref, view.offset, view.count), ""}
+	memory, err := d.ReadMemory(view.ref, view.offset, view.count)
+	switch {
+	case errors.Is(err, types.ErrUnsupported):
+		lines = append(lines, "The debug adapter doesn't support reading memory")
+	case err != nil:
+		lines = append(lines, err.Error())
+	case len(memory.Data) == 0 && memory.Unreadable == 0:
+		lines = append(lines, "No memory")
+	default:
+		var b strings.Builder
+		dap.Hexdump(&b, memory)
+		lines = append(lines, strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")...)
+	}
+	if err := setSidebarLines(v, buf, lines); err != nil {
+		log.Printf("Error rendering memory: %s", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"sync"
 
 	"github.com/dradtke/debug-console/dap"
@@ -15,8 +16,7 @@ import (
 )
 
 const (
-	SignGroupBreakpoint = "debug-console-breakpoint"
-	SignNameBreakpoint  = "debug-console-breakpoint"
+	NamespaceBreakpoint = "debug-console-breakpoint"
 
 	SignGroupCurrentLocation = "debug-console-current-location"
 	SignNameCurrentLocation  = "debug-console-current-location"
@@ -26,16 +26,13 @@ var (
 	dapDir string
 )
 
-func SendConfiguration(v *nvim.Nvim, p *dap.Conn) error {
+func SendConfiguration(v *nvim.Nvim, d *dap.DAP, p *dap.Conn) error {
 	log.Print("Waiting for the initialized event...")
 	<-p.InitializedEventSeen()
 
 	log.Print("Setting breakpoints")
 
-	allBreakpointSigns, err := GetAllSigns(v, SignGroupBreakpoint)
-	if err != nil {
-		return fmt.Errorf("Error getting breakpoint signs: %w", err)
-	}
+	paths := d.Breakpoints.Paths()
 
 	var (
 		wg     sync.WaitGroup
@@ -49,31 +46,21 @@ func SendConfiguration(v *nvim.Nvim, p *dap.Conn) error {
 		errsMu.Unlock()
 	}
 
-	wg.Add(len(allBreakpointSigns))
+	wg.Add(len(paths))
 
-	for buffer, breakpointSigns := range allBreakpointSigns {
-		go func(buffer nvim.Buffer, breakpointSigns []SignInfo) {
+	for _, path := range paths {
+		go func(path string) {
 			defer util.Recover()
-			bufferPath, err := BufferPath(v, buffer)
-			if err != nil {
-				addErr(fmt.Errorf("SendConfiguration: %w", err))
-			}
-			var breakpoints []types.SourceBreakpoint
-			for _, breakpointSign := range breakpointSigns {
-				breakpoints = append(breakpoints, types.SourceBreakpoint{
-					Line: breakpointSign.LineNumber,
-				})
+			if _, err := RefreshBreakpointLines(v, d, path); err != nil {
+				log.Printf("Error refreshing breakpoint lines: %s", err)
 			}
-			if _, err := p.SendRequest(types.NewSetBreakpointRequest(types.SetBreakpointArguments{
-				Source: types.Source{
-					Path: &bufferPath,
-				},
-				Breakpoints: breakpoints,
-			})); err != nil {
-				addErr(fmt.Errorf("Error setting breakpoints: %w", err))
+			if err := d.SyncBreakpoints(path); err != nil {
+				addErr(err)
+			} else if err := RenderBreakpointSigns(v, d, path); err != nil {
+				log.Printf("Error rendering breakpoint signs: %s", err)
 			}
 			wg.Done()
-		}(buffer, breakpointSigns)
+		}(path)
 	}
 
 	wg.Wait()
@@ -106,6 +93,20 @@ func setLogOutput() error {
 	return nil
 }
 
+// stateFile returns the path of a file used to persist state for the current
+// project, or an empty string if there is nowhere to put it.
+func stateFile(name string) string {
+	dir, err := dap.ProjectDir(os.Getenv("STATE_DIR"))
+	if err != nil {
+		log.Print(err)
+		return ""
+	}
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, name)
+}
+
 func HandlePanic() {
 	if r := recover(); r != nil {
 		log.Printf("Panic error: %s", r)
@@ -131,14 +132,30 @@ func Main(exe string) error {
 	}
 
 	d := &dap.DAP{
-		Exe: exe,
+		Exe:         exe,
+		Breakpoints: dap.NewBreakpointStore(stateFile("breakpoints.json")),
+		Watches:     dap.NewWatchStore(stateFile("watches.json")),
+	}
+	if err := d.Breakpoints.Load(); err != nil {
+		log.Printf("Error loading breakpoints: %s", err)
+	}
+	if err := d.Watches.Load(); err != nil {
+		log.Printf("Error loading watches: %s", err)
 	}
 
 	plugin.Main(func(p *plugin.Plugin) error {
 		tmux.ShellEscapeFunc = ShellEscape(p.Nvim)
 
 		d.EditorEventHandler = HandleEvent(p.Nvim, d) // this feels weird to do
+		d.EditorBreakpointsHandler = HandleBreakpointsChanged(p.Nvim, d)
+		d.EditorFrameHandler = func(frame types.StackFrame) {
+			ShowCurrentLocation(p.Nvim, d, frame)
+			RefreshSidebar(p.Nvim, d)
+			RefreshDisassembly(p.Nvim, d)
+		}
 		p.HandleAutocmd(&plugin.AutocmdOptions{Event: "VimLeave", Pattern: "*"}, d.Stop)
+		p.HandleAutocmd(&plugin.AutocmdOptions{Event: "BufReadPost", Pattern: "*", Eval: "expand('<afile>:p')"}, RestoreBreakpointSigns(p.Nvim, d))
+		p.HandleAutocmd(&plugin.AutocmdOptions{Event: "BufWritePost", Pattern: "*", Eval: "expand('<afile>:p')"}, SaveBreakpointLines(p.Nvim, d))
 		RegisterCommands(p, d)
 		RegisterFunctions(p, d)
 		return nil
@@ -0,0 +1,69 @@
+package nvim
+
+import (
+	"errors"
+	"log"
+	"sync"
+
+	"github.com/dradtke/debug-console/util"
+	"github.com/neovim/go-client/nvim"
+)
+
+var (
+	pickers      = make(map[int]func(int) error)
+	pickersMu    sync.Mutex
+	nextPickerID int
+)
+
+// Pick asks the user to choose one of items using vim.ui.select, and calls
+// onChoice with the index of the chosen item. onChoice is not called if the
+// selection is cancelled.
+func Pick(v *nvim.Nvim, prompt string, items []string, onChoice func(int) error) error {
+	if len(items) == 0 {
+		return errors.New("nothing to pick from")
+	}
+
+	pickersMu.Lock()
+	nextPickerID++
+	id := nextPickerID
+	pickers[id] = onChoice
+	pickersMu.Unlock()
+
+	// The selection is scheduled so that this call returns immediately, and
+	// the result comes back through DebugConsolePicked.
+	return v.ExecLua(`
+		local id, prompt, items = ...
+		vim.schedule(function()
+			vim.ui.select(items, {prompt = prompt}, function(_, idx)
+				vim.fn.DebugConsolePicked(id, idx or 0)
+			end)
+		end)
+	`, nil, id, prompt, items)
+}
+
+// Picked receives the result of a selection started by Pick. The index is
+// 1-based, with 0 meaning that the selection was cancelled.
+func Picked(v *nvim.Nvim, args []int) error {
+	if len(args) != 2 {
+		return errors.New("expected exactly two arguments")
+	}
+	id, idx := args[0], args[1]
+
+	pickersMu.Lock()
+	onChoice := pickers[id]
+	delete(pickers, id)
+	pickersMu.Unlock()
+
+	if onChoice == nil || idx == 0 {
+		return nil
+	}
+
+	go func() {
+		defer util.Recover()
+		if err := onChoice(idx - 1); err != nil {
+			log.Print(err)
+			Notify(v, err.Error(), nvim.LogErrorLevel)
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,101 @@
+package nvim
+
+import (
+	"fmt"
+
+	"github.com/dradtke/debug-console/dap"
+	"github.com/dradtke/debug-console/types"
+	"github.com/neovim/go-client/nvim"
+)
+
+type quickfixItem struct {
+	Filename string `msgpack:"filename,omitempty"`
+	Bufnr    int    `msgpack:"bufnr,omitempty"`
+	Line     int    `msgpack:"lnum"`
+	Column   int    `msgpack:"col,omitempty"`
+	Text     string `msgpack:"text"`
+}
+
+func frameQuickfixItem(frame types.StackFrame, text string) quickfixItem {
+	item := quickfixItem{Line: frame.Line, Column: frame.Column, Text: text}
+	if buf := loadedSourceBuffer(frame.Source); buf != 0 {
+		item.Bufnr = int(buf)
+	} else if frame.Source != nil && frame.Source.Path != nil {
+		item.Filename = *frame.Source.Path
+	}
+	return item
+}
+
+// setQuickfix replaces the quickfix list, or the current window's location
+// list if loclist is true, and opens it.
+func setQuickfix(v *nvim.Nvim, title string, items []quickfixItem, loclist bool) error {
+	what := map[string]any{"title": title, "items": items}
+	if loclist {
+		if err := v.Call("setloclist", nil, 0, []any{}, "r", what); err != nil {
+			return err
+		}
+		return v.Command("lopen")
+	}
+	if err := v.Call("setqflist", nil, []any{}, "r", what); err != nil {
+		return err
+	}
+	return v.Command("copen")
+}
+
+// StackToQuickfix puts every frame of the selected thread's stack in the
+// quickfix list, or the location list with a bang.
+func StackToQuickfix(d *dap.DAP) any {
+	return func(v *nvim.Nvim, bang bool) error {
+		if !d.HasSession() {
+			Notify(v, "No process running", nvim.LogWarnLevel)
+			return nil
+		}
+		stack, err := d.StackTrace(0, 0, 0)
+		if err != nil {
+			return fmt.Errorf("StackToQuickfix: %w", err)
+		}
+
+		items := make([]quickfixItem, len(stack.StackFrames))
+		for i, frame := range stack.StackFrames {
+			items[i] = frameQuickfixItem(frame, fmt.Sprintf("#%d %s", i, frame.Name))
+		}
+		title := fmt.Sprintf("Stack of thread %d", d.SelectedThread())
+		if err := setQuickfix(v, title, items, bang); err != nil {
+			return fmt.Errorf("StackToQuickfix: %w", err)
+		}
+		return nil
+	}
+}
+
+// ThreadsToQuickfix puts the top frame of every thread in the quickfix list,
+// or the location list with a bang. Threads that aren't stopped are skipped.
+func ThreadsToQuickfix(d *dap.DAP) any {
+	return func(v *nvim.Nvim, bang bool) error {
+		if !d.HasSession() {
+			Notify(v, "No process running", nvim.LogWarnLevel)
+			return nil
+		}
+		threads, err := d.Threads()
+		if err != nil {
+			return fmt.Errorf("ThreadsToQuickfix: %w", err)
+		}
+
+		var items []quickfixItem
+		for _, thread := range threads {
+			stack, err := d.StackTrace(thread.ID, 0, 1)
+			if err != nil || len(stack.StackFrames) == 0 {
+				continue
+			}
+			frame := stack.StackFrames[0]
+			items = append(items, frameQuickfixItem(frame, fmt.Sprintf("[%d] %s: %s", thread.ID, thread.Name, frame.Name)))
+		}
+		if len(items) == 0 {
+			Notify(v, "No stopped threads", nvim.LogWarnLevel)
+			return nil
+		}
+		if err := setQuickfix(v, "Threads", items, bang); err != nil {
+			return fmt.Errorf("ThreadsToQuickfix: %w", err)
+		}
+		return nil
+	}
+}
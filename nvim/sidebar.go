@@ -0,0 +1,451 @@
+package nvim
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/dradtke/debug-console/dap"
+	"github.com/dradtke/debug-console/types"
+	"github.com/dradtke/debug-console/util"
+	"github.com/neovim/go-client/nvim"
+)
+
+const (
+	SidebarVariables = "variables"
+	SidebarStack     = "stack"
+	SidebarThreads   = "threads"
+)
+
+var sidebarViews = []string{SidebarVariables, SidebarStack, SidebarThreads}
+
+// sidebar tracks the scratch buffers that show the program's state, along
+// with what each line of the stack and threads buffers refers to.
+var sidebar struct {
+	sync.Mutex
+	bufs    map[string]nvim.Buffer
+	frames  []types.StackFrame
+	threads []types.Thread
+	// expanded holds the paths of expanded variables, so that they stay
+	// expanded after the program stops again.
+	expanded map[string]bool
+}
+
+// ToggleSidebar opens the sidebar buffers in a vertical split, or closes them
+// if they're already open. The views to show can be given as arguments, and
+// default to all of them.
+func ToggleSidebar(d *dap.DAP) any {
+	return func(v *nvim.Nvim, args []string) error {
+		views := args
+		if len(views) == 0 {
+			views = sidebarViews
+		}
+		for _, view := range views {
+			if !validSidebarView(view) {
+				return fmt.Errorf("ToggleSidebar: unknown view %q, expected one of: %s", view, strings.Join(sidebarViews, ", "))
+			}
+		}
+
+		var closed bool
+		if err := v.ExecLua(`
+			local views = ...
+			local names = {}
+			for _, view in ipairs(views) do
+				names['debug-console://' .. view] = true
+			end
+			local closed = false
+			for _, win in ipairs(vim.api.nvim_list_wins()) do
+				local name = vim.api.nvim_buf_get_name(vim.api.nvim_win_get_buf(win))
+				if names[name] and #vim.api.nvim_list_wins() > 1 then
+					vim.api.nvim_win_close(win, false)
+					closed = true
+				end
+			end
+			return closed
+		`, &closed, sidebarViews); err != nil {
+			return fmt.Errorf("ToggleSidebar: %w", err)
+		}
+		if closed {
+			return nil
+		}
+
+		var bufs map[string]int
+		if err := v.ExecLua(`
+			local views = ...
+			local bufs = {}
+			local prev = vim.api.nvim_get_current_win()
+			vim.cmd 'botright 50vsplit'
+			for i, view in ipairs(views) do
+				if i > 1 then
+					vim.cmd 'belowright split'
+				end
+				local name = 'debug-console://' .. view
+				local buf = vim.fn.bufnr(name)
+				if buf == -1 then
+					buf = vim.api.nvim_create_buf(false, true)
+					vim.api.nvim_buf_set_name(buf, name)
+					vim.bo[buf].bufhidden = 'hide'
+					vim.bo[buf].modifiable = false
+					vim.bo[buf].filetype = 'debug-console-' .. view
+					vim.keymap.set('n', '<CR>', function()
+						vim.fn.DebugConsoleSidebarAction(view, buf, vim.fn.line('.'))
+					end, {buffer = buf, nowait = true})
+					if view == 'variables' then
+						vim.keymap.set('n', 'm', function()
+							vim.fn.DebugConsoleNodeMemory(buf, vim.fn.line('.'))
+						end, {buffer = buf, nowait = true})
+					end
+					vim.keymap.set('n', 'q', '<cmd>close<cr>', {buffer = buf, nowait = true})
+				end
+				vim.api.nvim_win_set_buf(0, buf)
+				vim.wo.winfixwidth = true
+				vim.wo.number = false
+				vim.wo.relativenumber = false
+				vim.wo.signcolumn = 'no'
+				bufs[view] = buf
+			end
+			vim.api.nvim_set_current_win(prev)
+			return bufs
+		`, &bufs, views); err != nil {
+			return fmt.Errorf("ToggleSidebar: %w", err)
+		}
+
+		sidebar.Lock()
+		if sidebar.bufs == nil {
+			sidebar.bufs = make(map[string]nvim.Buffer)
+		}
+		for view, buf := range bufs {
+			sidebar.bufs[view] = nvim.Buffer(buf)
+		}
+		sidebar.Unlock()
+
+		RefreshSidebar(v, d, views...)
+		return nil
+	}
+}
+
+func validSidebarView(view string) bool {
+	for _, v := range sidebarViews {
+		if v == view {
+			return true
+		}
+	}
+	return false
+}
+
+// RefreshSidebar updates the given sidebar views, or all of them if none are
+// given. Views whose buffer was never opened are skipped.
+func RefreshSidebar(v *nvim.Nvim, d *dap.DAP, views ...string) {
+	if len(views) == 0 {
+		views = sidebarViews
+	}
+	for _, view := range views {
+		sidebar.Lock()
+		buf, ok := sidebar.bufs[view]
+		sidebar.Unlock()
+		if !ok {
+			continue
+		}
+		if valid, err := v.IsBufferValid(buf); err != nil || !valid {
+			sidebar.Lock()
+			delete(sidebar.bufs, view)
+			sidebar.Unlock()
+			forgetTree(buf)
+			continue
+		}
+
+		var err error
+		switch view {
+		case SidebarVariables:
+			err = refreshVariables(v, d, buf)
+		case SidebarStack:
+			err = refreshStack(v, d, buf)
+		case SidebarThreads:
+			err = refreshThreads(v, d, buf)
+		}
+		if err != nil {
+			log.Printf("Error refreshing %s: %s", view, err)
+		}
+	}
+}
+
+// ClearSidebar shows a message in place of the program's state, such as while
+// it's running.
+func ClearSidebar(v *nvim.Nvim, message string) {
+	sidebar.Lock()
+	bufs := make(map[string]nvim.Buffer, len(sidebar.bufs))
+	for view, buf := range sidebar.bufs {
+		bufs[view] = buf
+	}
+	sidebar.frames = nil
+	sidebar.Unlock()
+
+	for view, buf := range bufs {
+		if view == SidebarVariables {
+			rememberExpanded(buf)
+			forgetTree(buf)
+		}
+		if view == SidebarThreads {
+			// The thread list is still meaningful while running.
+			continue
+		}
+		if err := setSidebarLines(v, buf, []string{message}); err != nil {
+			log.Printf("Error clearing %s: %s", view, err)
+		}
+	}
+}
+
+func setSidebarLines(v *nvim.Nvim, buf nvim.Buffer, lines []string) error {
+	return v.ExecLua(`
+		local buf, lines = ...
+		if not vim.api.nvim_buf_is_valid(buf) then
+			return
+		end
+		vim.bo[buf].modifiable = true
+		vim.api.nvim_buf_set_lines(buf, 0, -1, false, lines)
+		vim.bo[buf].modifiable = false
+	`, nil, int(buf), lines)
+}
+
+// rememberExpanded records what is expanded in the variables buffer, so that
+// it can be expanded again once the buffer's tree is replaced.
+func rememberExpanded(buf nvim.Buffer) {
+	treesMu.Lock()
+	t := trees[buf]
+	treesMu.Unlock()
+	if t == nil {
+		return
+	}
+	expanded := make(map[string]bool)
+	t.mu.Lock()
+	collectExpanded(t.roots, "", expanded)
+	t.mu.Unlock()
+	sidebar.Lock()
+	sidebar.expanded = expanded
+	sidebar.Unlock()
+}
+
+func refreshVariables(v *nvim.Nvim, d *dap.DAP, buf nvim.Buffer) error {
+	rememberExpanded(buf)
+
+	scopes, err := d.Scopes()
+	if err != nil {
+		forgetTree(buf)
+		return setSidebarLines(v, buf, []string{"Not stopped"})
+	}
+
+	sidebar.Lock()
+	expanded := sidebar.expanded
+	sidebar.Unlock()
+
+	roots := make([]*variableNode, len(scopes))
+	for i, scope := range scopes {
+		roots[i] = &variableNode{
+			Name:    scope.Name,
+			Ref:     scope.VariablesReference,
+			Named:   scope.NamedVariables,
+			Indexed: scope.IndexedVariables,
+		}
+		// Scopes are expanded by default, unless they're expensive to fetch
+		// or were collapsed by the user.
+		path := treePath("", scope.Name)
+		if isExpanded, seen := expanded[path]; (seen && isExpanded) || (!seen && !scope.Expensive) {
+			if err := expandNode(d, roots[i], path, expanded); err != nil {
+				return err
+			}
+		}
+	}
+	return ShowTree(v, buf, roots)
+}
+
+// collectExpanded records whether each node with children is expanded, keyed
+// by its path of names.
+func collectExpanded(nodes []*variableNode, prefix string, expanded map[string]bool) {
+	for _, node := range nodes {
+		if node.Ref == 0 || node.more {
+			continue
+		}
+		path := treePath(prefix, node.Name)
+		expanded[path] = node.expanded
+		if node.expanded {
+			collectExpanded(node.children, path, expanded)
+		}
+	}
+}
+
+// expandNode loads a node's children, and expands the ones that were expanded
+// before.
+func expandNode(d *dap.DAP, node *variableNode, path string, expanded map[string]bool) error {
+	children, err := loadChildren(d, node, 0)
+	if err != nil {
+		return err
+	}
+	node.children, node.expanded = children, true
+	for _, child := range children {
+		childPath := treePath(path, child.Name)
+		if child.Ref > 0 && expanded[childPath] {
+			if err := expandNode(d, child, childPath, expanded); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func treePath(prefix, name string) string {
+	return prefix + "\x00" + name
+}
+
+func refreshStack(v *nvim.Nvim, d *dap.DAP, buf nvim.Buffer) error {
+	stack, err := d.StackTrace(0, 0, 0)
+	if err != nil {
+		sidebar.Lock()
+		sidebar.frames = nil
+		sidebar.Unlock()
+		return setSidebarLines(v, buf, []string{"Not stopped"})
+	}
+
+	d.RLock()
+	selected := d.SelectedFrameIndex
+	d.RUnlock()
+
+	lines := make([]string, len(stack.StackFrames))
+	for i, frame := range stack.StackFrames {
+		marker := " "
+		if i == selected {
+			marker = "*"
+		}
+		location := "<unknown>"
+		if frame.Source != nil && frame.Source.Path != nil {
+			location = filepath.Base(*frame.Source.Path)
+		} else if frame.Source != nil && frame.Source.Name != nil {
+			location = *frame.Source.Name
+		}
+		lines[i] = fmt.Sprintf("%s #%-3d %s at %s:%d", marker, i, frame.Name, location, frame.Line)
+	}
+
+	sidebar.Lock()
+	sidebar.frames = stack.StackFrames
+	sidebar.Unlock()
+	if len(lines) == 0 {
+		lines = []string{"No stack frames"}
+	}
+	return setSidebarLines(v, buf, lines)
+}
+
+func refreshThreads(v *nvim.Nvim, d *dap.DAP, buf nvim.Buffer) error {
+	threads, err := d.Threads()
+	if err != nil {
+		sidebar.Lock()
+		sidebar.threads = nil
+		sidebar.Unlock()
+		return setSidebarLines(v, buf, []string{"No process running"})
+	}
+
+	selected := d.SelectedThread()
+	lines := make([]string, len(threads))
+	for i, thread := range threads {
+		marker := " "
+		if thread.ID == selected {
+			marker = "*"
+		}
+		lines[i] = fmt.Sprintf("%s [%d] %s", marker, thread.ID, thread.Name)
+	}
+
+	sidebar.Lock()
+	sidebar.threads = threads
+	sidebar.Unlock()
+	if len(lines) == 0 {
+		lines = []string{"No threads"}
+	}
+	return setSidebarLines(v, buf, lines)
+}
+
+// SidebarAction handles <CR> in a sidebar buffer. In the variables view it
+// expands or collapses a variable, in the stack view it selects a frame and
+// jumps to its source, and in the threads view it selects a thread.
+func SidebarAction(d *dap.DAP) any {
+	return func(v *nvim.Nvim, args []any) error {
+		if len(args) != 3 {
+			return errors.New("expected exactly three arguments")
+		}
+		view, _ := args[0].(string)
+		buf, line := toInt(args[1]), toInt(args[2])-1
+
+		if view == SidebarVariables {
+			toggleTreeNode(v, d, nvim.Buffer(buf), line)
+			return nil
+		}
+
+		// Selecting things sends requests, so don't block the editor.
+		go func() {
+			defer util.Recover()
+			var err error
+			switch view {
+			case SidebarStack:
+				err = selectSidebarFrame(v, d, line)
+			case SidebarThreads:
+				err = selectSidebarThread(v, d, line)
+			}
+			if err != nil {
+				log.Print(err)
+				Notify(v, err.Error(), nvim.LogErrorLevel)
+			}
+		}()
+		return nil
+	}
+}
+
+func selectSidebarFrame(v *nvim.Nvim, d *dap.DAP, n int) error {
+	sidebar.Lock()
+	frames := sidebar.frames
+	sidebar.Unlock()
+	if n < 0 || n >= len(frames) {
+		return nil
+	}
+
+	// Selecting the frame refreshes the sidebar through the frame handler.
+	frame, err := d.SelectFrame(n)
+	if err != nil {
+		return fmt.Errorf("selectSidebarFrame: %w", err)
+	}
+	if !hasSource(frame.Source) {
+		return nil
+	}
+	if err := v.Command("wincmd p"); err != nil {
+		return fmt.Errorf("selectSidebarFrame: %w", err)
+	}
+	return openSource(v, d, *frame.Source, frame.Line)
+}
+
+func selectSidebarThread(v *nvim.Nvim, d *dap.DAP, n int) error {
+	sidebar.Lock()
+	threads := sidebar.threads
+	sidebar.Unlock()
+	if n < 0 || n >= len(threads) {
+		return nil
+	}
+
+	d.SelectThread(threads[n].ID)
+	// The new thread's stack is only available if it's stopped.
+	if _, err := d.SelectFrame(0); err != nil {
+		RefreshSidebar(v, d)
+	}
+	return nil
+}
+
+// toInt converts a number decoded from msgpack, which may be any integer type.
+func toInt(x any) int {
+	switch n := x.(type) {
+	case int64:
+		return int(n)
+	case uint64:
+		return int(n)
+	case int:
+		return n
+	}
+	return 0
+}
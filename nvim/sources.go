@@ -0,0 +1,189 @@
+package nvim
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/dradtke/debug-console/dap"
+	"github.com/dradtke/debug-console/types"
+	"github.com/neovim/go-client/nvim"
+)
+
+// PickSource lets the user pick one of the program's loaded sources, and
+// opens it.
+func PickSource(d *dap.DAP) any {
+	return func(v *nvim.Nvim) error {
+		if !d.HasSession() {
+			Notify(v, "No process running", nvim.LogWarnLevel)
+			return nil
+		}
+		sources, err := d.LoadedSources()
+		if errors.Is(err, types.ErrUnsupported) {
+			Notify(v, "The debug adapter doesn't support listing loaded sources", nvim.LogWarnLevel)
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("PickSource: %w", err)
+		}
+		if len(sources) == 0 {
+			Notify(v, "No loaded sources", nvim.LogWarnLevel)
+			return nil
+		}
+
+		items := make([]string, len(sources))
+		for i, source := range sources {
+			switch {
+			case source.Path != nil:
+				items[i] = *source.Path
+			case source.Name != nil:
+				items[i] = *source.Name
+			default:
+				items[i] = "<unknown>"
+			}
+		}
+		return Pick(v, "Open source", items, func(i int) error {
+			return openSource(v, d, sources[i], 0)
+		})
+	}
+}
+
+var (
+	// sourceBufs holds the buffers of sources whose content comes from the
+	// debug adapter, keyed by source reference.
+	sourceBufs   = make(map[int]nvim.Buffer)
+	sourceBufsMu sync.Mutex
+)
+
+// sourceBufferPrefix starts the names of buffers with sources that the debug
+// adapter provides.
+const sourceBufferPrefix = "debug-console://source/"
+
+// isSourceBuffer reports whether path is the name of a buffer with a source
+// that the debug adapter provides.
+func isSourceBuffer(path string) bool {
+	return strings.HasPrefix(path, sourceBufferPrefix)
+}
+
+// staleSourceBuffer reports whether path is the name of a buffer with a source
+// from an earlier session, whose reference the debug adapter no longer knows.
+func staleSourceBuffer(d *dap.DAP, path string) bool {
+	if !isSourceBuffer(path) {
+		return false
+	}
+	_, ok := d.Breakpoints.AdapterSource(path)
+	return !ok
+}
+
+// hasSource reports whether a source can be opened.
+func hasSource(source *types.Source) bool {
+	return source != nil && (source.Path != nil || source.Reference() > 0)
+}
+
+// openSource opens a source in the current window, at line if it's positive.
+// Sources with a reference are loaded from the debug adapter.
+func openSource(v *nvim.Nvim, d *dap.DAP, source types.Source, line int) error {
+	if source.Reference() > 0 {
+		buf, err := sourceBuffer(v, d, source)
+		if err != nil {
+			return err
+		}
+		return v.ExecLua(`
+			local buf, line = ...
+			vim.api.nvim_win_set_buf(0, buf)
+			if line > 0 then
+				vim.api.nvim_win_set_cursor(0, {math.min(line, vim.api.nvim_buf_line_count(buf)), 0})
+			end
+		`, nil, int(buf), line)
+	}
+	if source.Path == nil {
+		return errors.New("source has no path")
+	}
+	return v.ExecLua(`
+		local path, line = ...
+		local cmd = 'keepalt edit '
+		if line > 0 then
+			cmd = cmd .. '+' .. line .. ' '
+		end
+		vim.cmd(cmd .. vim.fn.fnameescape(path))
+	`, nil, *source.Path, line)
+}
+
+// sourceBuffer returns a read-only buffer with the content of a source that
+// the debug adapter provides, fetching it the first time.
+func sourceBuffer(v *nvim.Nvim, d *dap.DAP, source types.Source) (nvim.Buffer, error) {
+	ref := source.Reference()
+	if buf := loadedSourceBuffer(&source); buf != 0 {
+		if valid, err := v.IsBufferValid(buf); err == nil && valid {
+			return buf, nil
+		}
+	}
+
+	resp, err := d.Source(source)
+	if err != nil {
+		return 0, fmt.Errorf("Error loading source: %w", err)
+	}
+	lines := strings.Split(strings.ReplaceAll(resp.Content, "\r\n", "\n"), "\n")
+
+	name := "source"
+	if source.Name != nil {
+		name = *source.Name
+	} else if source.Path != nil {
+		name = filepath.Base(*source.Path)
+	}
+	bufname := fmt.Sprintf("%s%d/%s", sourceBufferPrefix, ref, name)
+	var buf int
+	if err := v.ExecLua(`
+		local bufname, name, lines, mime = ...
+		local buf = vim.fn.bufnr(bufname)
+		if buf == -1 then
+			buf = vim.api.nvim_create_buf(true, true)
+			vim.api.nvim_buf_set_name(buf, bufname)
+			vim.bo[buf].bufhidden = 'hide'
+		end
+		vim.bo[buf].readonly = false
+		vim.bo[buf].modifiable = true
+		vim.api.nvim_buf_set_lines(buf, 0, -1, false, lines)
+		vim.bo[buf].modifiable = false
+		vim.bo[buf].readonly = true
+		vim.bo[buf].modified = false
+		local ft = vim.filetype.match({filename = name, contents = lines})
+		if not ft and mime ~= '' then
+			ft = mime:match('^%a+/x%-(.+)$') or mime:match('^%a+/(.+)$')
+		end
+		if ft then
+			vim.bo[buf].filetype = ft
+		end
+		return buf
+	`, &buf, bufname, name, lines, resp.MimeType); err != nil {
+		return 0, fmt.Errorf("Error creating source buffer: %w", err)
+	}
+
+	sourceBufsMu.Lock()
+	sourceBufs[ref] = nvim.Buffer(buf)
+	sourceBufsMu.Unlock()
+	// Breakpoints in the buffer are set by its name.
+	d.Breakpoints.SetAdapterSource(bufname, source)
+	return nvim.Buffer(buf), nil
+}
+
+// loadedSourceBuffer returns the buffer that a source with a reference was
+// loaded into, or 0 if it hasn't been.
+func loadedSourceBuffer(source *types.Source) nvim.Buffer {
+	if source == nil || source.Reference() == 0 {
+		return 0
+	}
+	sourceBufsMu.Lock()
+	defer sourceBufsMu.Unlock()
+	return sourceBufs[source.Reference()]
+}
+
+// forgetSources drops the loaded sources once the session ends, since source
+// references are only valid within a session. Their buffers are reloaded if
+// the next session opens the same reference.
+func forgetSources() {
+	sourceBufsMu.Lock()
+	sourceBufs = make(map[int]nvim.Buffer)
+	sourceBufsMu.Unlock()
+}
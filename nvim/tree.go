@@ -0,0 +1,263 @@
+package nvim
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/dradtke/debug-console/dap"
+	"github.com/dradtke/debug-console/types"
+	"github.com/dradtke/debug-console/util"
+	"github.com/neovim/go-client/nvim"
+)
+
+// treePageSize is the number of indexed children, such as slice elements,
+// that are loaded at a time.
+const treePageSize = 100
+
+// variableNode is one line of a variables tree. Nodes with a variables
+// reference can be expanded to show their children.
+type variableNode struct {
+	Name, Type, Value string
+	Ref               int
+	Named, Indexed    *int
+	// MemoryRef is the memory reference of the variable's value, if any.
+	MemoryRef string
+
+	expanded bool
+	children []*variableNode
+	// more is set on the placeholder that loads the next page of its parent's
+	// indexed children, starting at start.
+	more   bool
+	start  int
+	parent *variableNode
+}
+
+func newVariableNode(v types.Variable) *variableNode {
+	node := &variableNode{
+		Name:    v.Name,
+		Type:    v.Type,
+		Value:   v.Value,
+		Ref:     v.VariablesReference,
+		Named:   v.NamedVariables,
+		Indexed: v.IndexedVariables,
+	}
+	if v.MemoryReference != nil {
+		node.MemoryRef = *v.MemoryReference
+	}
+	return node
+}
+
+// variableTree renders variables into a buffer, and expands them in place.
+type variableTree struct {
+	mu      sync.Mutex
+	roots   []*variableNode
+	visible []*variableNode
+	depths  []int
+}
+
+var (
+	trees   = make(map[nvim.Buffer]*variableTree)
+	treesMu sync.Mutex
+)
+
+// ShowTree renders a tree into a buffer, replacing whatever tree it had.
+func ShowTree(v *nvim.Nvim, buf nvim.Buffer, roots []*variableNode) error {
+	t := &variableTree{roots: roots}
+	treesMu.Lock()
+	trees[buf] = t
+	treesMu.Unlock()
+	return t.render(v, buf)
+}
+
+// forgetTree stops tracking a buffer's tree, once the buffer is gone.
+func forgetTree(buf nvim.Buffer) {
+	treesMu.Lock()
+	delete(trees, buf)
+	treesMu.Unlock()
+}
+
+func (t *variableTree) render(v *nvim.Nvim, buf nvim.Buffer) error {
+	t.mu.Lock()
+	t.visible, t.depths = nil, nil
+	var walk func(nodes []*variableNode, depth int)
+	walk = func(nodes []*variableNode, depth int) {
+		for _, node := range nodes {
+			t.visible = append(t.visible, node)
+			t.depths = append(t.depths, depth)
+			if node.expanded {
+				walk(node.children, depth+1)
+			}
+		}
+	}
+	walk(t.roots, 0)
+	lines := make([]string, len(t.visible))
+	for i, node := range t.visible {
+		lines[i] = strings.Repeat("  ", t.depths[i]) + node.label()
+	}
+	t.mu.Unlock()
+
+	if len(lines) == 0 {
+		lines = []string{"(empty)"}
+	}
+	// Floating windows are resized to fit the new contents.
+	return v.ExecLua(`
+		local buf, lines = ...
+		vim.bo[buf].modifiable = true
+		vim.api.nvim_buf_set_lines(buf, 0, -1, false, lines)
+		vim.bo[buf].modifiable = false
+		for _, win in ipairs(vim.fn.win_findbuf(buf)) do
+			if vim.api.nvim_win_get_config(win).relative ~= '' then
+				local width = 1
+				for _, line in ipairs(lines) do
+					width = math.max(width, vim.fn.strdisplaywidth(line))
+				end
+				vim.api.nvim_win_set_config(win, {
+					width = math.min(width, math.floor(vim.o.columns * 0.8)),
+					height = math.min(#lines, math.floor(vim.o.lines * 0.5)),
+				})
+			end
+		end
+	`, nil, int(buf), lines)
+}
+
+func (node *variableNode) label() string {
+	if node.more {
+		return fmt.Sprintf("... %d more", *node.parent.Indexed-node.start)
+	}
+	marker := "  "
+	if node.Ref > 0 && node.expanded {
+		marker = "▾ "
+	} else if node.Ref > 0 {
+		marker = "▸ "
+	}
+	var b strings.Builder
+	b.WriteString(marker)
+	b.WriteString(node.Name)
+	if node.Type != "" {
+		fmt.Fprintf(&b, " (%s)", node.Type)
+	}
+	if node.Value != "" {
+		// Values can contain newlines, which buffer lines can't.
+		fmt.Fprintf(&b, " = %s", strings.ReplaceAll(node.Value, "\n", `\n`))
+	}
+	return b.String()
+}
+
+// toggle expands or collapses the node on a line, loading its children the
+// first time.
+func (t *variableTree) toggle(d *dap.DAP, line int) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if line < 0 || line >= len(t.visible) {
+		return nil
+	}
+	node := t.visible[line]
+
+	if node.more {
+		children, err := loadChildren(d, node.parent, node.start)
+		if err != nil {
+			return err
+		}
+		siblings := node.parent.children
+		node.parent.children = append(siblings[:len(siblings)-1:len(siblings)-1], children...)
+		return nil
+	}
+	if node.Ref == 0 {
+		return nil
+	}
+	if node.expanded {
+		node.expanded = false
+		return nil
+	}
+	if node.children == nil {
+		children, err := loadChildren(d, node, 0)
+		if err != nil {
+			return err
+		}
+		node.children = children
+	}
+	node.expanded = true
+	return nil
+}
+
+// loadChildren fetches a node's children. If the number of indexed children
+// is known, they're loaded a page at a time starting at start, and the named
+// children are only loaded with the first page.
+func loadChildren(d *dap.DAP, node *variableNode, start int) ([]*variableNode, error) {
+	var children []*variableNode
+	add := func(args types.VariablesArguments) error {
+		variables, err := d.Variables(args)
+		if err != nil {
+			return err
+		}
+		for _, v := range variables {
+			child := newVariableNode(v)
+			child.parent = node
+			children = append(children, child)
+		}
+		return nil
+	}
+
+	if node.Indexed == nil || *node.Indexed == 0 {
+		return children, add(types.VariablesArguments{VariablesReference: node.Ref})
+	}
+
+	if start == 0 && (node.Named == nil || *node.Named > 0) {
+		if err := add(types.VariablesArguments{VariablesReference: node.Ref, Filter: "named"}); err != nil {
+			return nil, err
+		}
+	}
+	count := treePageSize
+	if *node.Indexed-start < count {
+		count = *node.Indexed - start
+	}
+	if err := add(types.VariablesArguments{
+		VariablesReference: node.Ref,
+		Filter:             "indexed",
+		Start:              start,
+		Count:              count,
+	}); err != nil {
+		return nil, err
+	}
+	if start+count < *node.Indexed {
+		children = append(children, &variableNode{more: true, start: start + count, parent: node})
+	}
+	return children, nil
+}
+
+// ToggleTreeNode expands or collapses the tree node on a line of a buffer. It
+// is called from buffer-local mappings with the buffer and 1-based line.
+func ToggleTreeNode(d *dap.DAP) any {
+	return func(v *nvim.Nvim, args []int) error {
+		if len(args) != 2 {
+			return errors.New("expected exactly two arguments")
+		}
+		toggleTreeNode(v, d, nvim.Buffer(args[0]), args[1]-1)
+		return nil
+	}
+}
+
+func toggleTreeNode(v *nvim.Nvim, d *dap.DAP, buf nvim.Buffer, line int) {
+	treesMu.Lock()
+	t := trees[buf]
+	treesMu.Unlock()
+	if t == nil {
+		return
+	}
+
+	// Loading children can take a while, so don't block the editor.
+	go func() {
+		defer util.Recover()
+		if err := t.toggle(d, line); err != nil {
+			log.Printf("Error expanding variable: %s", err)
+			Notify(v, err.Error(), nvim.LogErrorLevel)
+			return
+		}
+		if err := t.render(v, buf); err != nil {
+			log.Printf("Error rendering variables: %s", err)
+		}
+	}()
+}
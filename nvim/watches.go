@@ -0,0 +1,78 @@
+package nvim
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dradtke/debug-console/console"
+	"github.com/dradtke/debug-console/dap"
+	"github.com/neovim/go-client/nvim"
+)
+
+// AddWatch adds a watch expression, which the console shows every time the
+// program stops.
+func AddWatch(d *dap.DAP) any {
+	return func(v *nvim.Nvim, args []string) error {
+		w, err := d.AddWatch(strings.Join(args, " "))
+		if err != nil {
+			return fmt.Errorf("AddWatch: %w", err)
+		}
+		Notify(v, formatWatches([]dap.Watch{w}), nvim.LogInfoLevel)
+		return nil
+	}
+}
+
+// RemoveWatch removes the watches with the given IDs, or lets the user pick
+// one if none are given.
+func RemoveWatch(d *dap.DAP) any {
+	return func(v *nvim.Nvim, args []string) error {
+		if len(args) > 0 {
+			ids := make([]int, len(args))
+			for i, arg := range args {
+				id, err := strconv.Atoi(arg)
+				if err != nil {
+					return fmt.Errorf("RemoveWatch: invalid watch id: %s", arg)
+				}
+				ids[i] = id
+			}
+			if err := d.RemoveWatches(ids); err != nil {
+				return fmt.Errorf("RemoveWatch: %w", err)
+			}
+			return nil
+		}
+
+		watches := d.Watches.All()
+		if len(watches) == 0 {
+			Notify(v, "No watches", nvim.LogWarnLevel)
+			return nil
+		}
+		items := make([]string, len(watches))
+		for i, w := range watches {
+			items[i] = fmt.Sprintf("[%d] %s", w.ID, w.Expression)
+		}
+		return Pick(v, "Remove watch", items, func(i int) error {
+			return d.RemoveWatches([]int{watches[i].ID})
+		})
+	}
+}
+
+// ListWatches shows the watch expressions with their values as of the last
+// stop.
+func ListWatches(d *dap.DAP) any {
+	return func(v *nvim.Nvim) error {
+		watches := d.Watches.All()
+		if len(watches) == 0 {
+			Notify(v, "No watches", nvim.LogInfoLevel)
+			return nil
+		}
+		Notify(v, formatWatches(watches), nvim.LogInfoLevel)
+		return nil
+	}
+}
+
+func formatWatches(watches []dap.Watch) string {
+	var b strings.Builder
+	console.FormatWatches(&b, watches)
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -26,3 +26,41 @@ type OutputEvent struct {
 	Category string `json:"category"`
 	Output   string `json:"output"`
 }
+
+// https://microsoft.github.io/debug-adapter-protocol/specification#Events_Breakpoint
+type BreakpointEvent struct {
+	Reason     string     `json:"reason"`
+	Breakpoint Breakpoint `json:"breakpoint"`
+}
+
+// https://microsoft.github.io/debug-adapter-protocol/specification#Events_Thread
+type ThreadEvent struct {
+	Reason   string `json:"reason"`
+	ThreadID int    `json:"threadId"`
+}
+
+// https://microsoft.github.io/debug-adapter-protocol/specification#Events_Invalidated
+type InvalidatedEvent struct {
+	Areas        []string `json:"areas,omitempty"`
+	ThreadID     *int     `json:"threadId,omitempty"`
+	StackFrameID *int     `json:"stackFrameId,omitempty"`
+}
+
+// https://microsoft.github.io/debug-adapter-protocol/specification#Events_Module
+type ModuleEvent struct {
+	Reason string `json:"reason"`
+	Module Module `json:"module"`
+}
+
+// https://microsoft.github.io/debug-adapter-protocol/specification#Events_LoadedSource
+type LoadedSourceEvent struct {
+	Reason string `json:"reason"`
+	Source Source `json:"source"`
+}
+
+// https://microsoft.github.io/debug-adapter-protocol/specification#Events_Memory
+type MemoryEvent struct {
+	MemoryReference string `json:"memoryReference"`
+	Offset          int    `json:"offset"`
+	Count           int    `json:"count"`
+}
@@ -7,3 +7,7 @@ func PtrBool(b bool) *bool {
 func PtrString(s string) *string {
 	return &s
 }
+
+func PtrInt(i int) *int {
+	return &i
+}
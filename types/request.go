@@ -34,6 +34,10 @@ type InitializeArguments struct {
 	ColumnsStartAt1                     bool   `json:"columnsStartAt1"`
 	SupportsRunInTerminalRequest        bool   `json:"supportsRunInTerminalRequest"`
 	SupportsArgsCanBeInterpretedByShell bool   `json:"supportsArgsCanBeInterpretedByShell"`
+	SupportsVariableType                bool   `json:"supportsVariableType,omitempty"`
+	SupportsInvalidatedEvent            bool   `json:"supportsInvalidatedEvent,omitempty"`
+	SupportsMemoryReferences            bool   `json:"supportsMemoryReferences,omitempty"`
+	SupportsMemoryEvent                 bool   `json:"supportsMemoryEvent,omitempty"`
 }
 
 func NewInitializeRequest(args InitializeArguments) Request {
@@ -64,16 +68,96 @@ func NewContinueRequest(args ContinueArguments) Request {
 	}
 }
 
-func NewStepInRequest() Request {
-	return newRequest("stepIn")
+type StepInArguments struct {
+	ThreadID     int    `json:"threadId"`
+	SingleThread bool   `json:"singleThread,omitempty"`
+	TargetID     *int   `json:"targetId,omitempty"`
+	Granularity  string `json:"granularity,omitempty"`
 }
 
-func NewStepOutRequest() Request {
-	return newRequest("stepOut")
+func NewStepInRequest(args StepInArguments) Request {
+	return struct {
+		request
+		Arguments StepInArguments `json:"arguments"`
+	}{
+		request:   newRequest("stepIn"),
+		Arguments: args,
+	}
+}
+
+type StepInTargetsArguments struct {
+	FrameID int `json:"frameId"`
+}
+
+func NewStepInTargetsRequest(args StepInTargetsArguments) Request {
+	return struct {
+		request
+		Arguments StepInTargetsArguments `json:"arguments"`
+	}{
+		request:   newRequest("stepInTargets"),
+		Arguments: args,
+	}
+}
+
+type StepOutArguments struct {
+	ThreadID     int    `json:"threadId"`
+	SingleThread bool   `json:"singleThread,omitempty"`
+	Granularity  string `json:"granularity,omitempty"`
+}
+
+func NewStepOutRequest(args StepOutArguments) Request {
+	return struct {
+		request
+		Arguments StepOutArguments `json:"arguments"`
+	}{
+		request:   newRequest("stepOut"),
+		Arguments: args,
+	}
 }
 
-func NewStepBackRequest() Request {
-	return newRequest("stepBack")
+type StepBackArguments struct {
+	ThreadID     int    `json:"threadId"`
+	SingleThread bool   `json:"singleThread,omitempty"`
+	Granularity  string `json:"granularity,omitempty"`
+}
+
+func NewStepBackRequest(args StepBackArguments) Request {
+	return struct {
+		request
+		Arguments StepBackArguments `json:"arguments"`
+	}{
+		request:   newRequest("stepBack"),
+		Arguments: args,
+	}
+}
+
+type ReverseContinueArguments struct {
+	ThreadID     int  `json:"threadId"`
+	SingleThread bool `json:"singleThread,omitempty"`
+}
+
+func NewReverseContinueRequest(args ReverseContinueArguments) Request {
+	return struct {
+		request
+		Arguments ReverseContinueArguments `json:"arguments"`
+	}{
+		request:   newRequest("reverseContinue"),
+		Arguments: args,
+	}
+}
+
+type PauseArguments struct {
+	ThreadID int `json:"threadId"`
+}
+
+func NewPauseRequest(args PauseArguments) Request {
+	return struct {
+		request
+		Arguments PauseArguments `json:"arguments"`
+	}{
+		request:   newRequest("pause"),
+		Arguments: args,
+	}
 }
 
 func NewThreadsRequest() Request {
@@ -84,6 +168,9 @@ type EvaluateArguments struct {
 	Expression string `json:"expression"`
 	Context    string `json:"context,omitempty"`
 	FrameID    int    `json:"frameId,omitempty"`
+	// Format is only honored if the debug adapter supports value formatting
+	// options.
+	Format *ValueFormat `json:"format,omitempty"`
 }
 
 func NewEvaluateRequest(args EvaluateArguments) Request {
@@ -96,10 +183,176 @@ func NewEvaluateRequest(args EvaluateArguments) Request {
 	}
 }
 
+type ScopesArguments struct {
+	FrameID int `json:"frameId"`
+}
+
+func NewScopesRequest(args ScopesArguments) Request {
+	return struct {
+		request
+		Arguments ScopesArguments `json:"arguments"`
+	}{
+		request:   newRequest("scopes"),
+		Arguments: args,
+	}
+}
+
+type VariablesArguments struct {
+	VariablesReference int `json:"variablesReference"`
+	// Filter is "indexed" or "named", and limits which children are returned.
+	Filter string       `json:"filter,omitempty"`
+	Start  int          `json:"start,omitempty"`
+	Count  int          `json:"count,omitempty"`
+	Format *ValueFormat `json:"format,omitempty"`
+}
+
+func NewVariablesRequest(args VariablesArguments) Request {
+	return struct {
+		request
+		Arguments VariablesArguments `json:"arguments"`
+	}{
+		request:   newRequest("variables"),
+		Arguments: args,
+	}
+}
+
+type SetVariableArguments struct {
+	VariablesReference int    `json:"variablesReference"`
+	Name               string `json:"name"`
+	Value              string `json:"value"`
+}
+
+func NewSetVariableRequest(args SetVariableArguments) Request {
+	return struct {
+		request
+		Arguments SetVariableArguments `json:"arguments"`
+	}{
+		request:   newRequest("setVariable"),
+		Arguments: args,
+	}
+}
+
+type SetExpressionArguments struct {
+	Expression string `json:"expression"`
+	Value      string `json:"value"`
+	FrameID    int    `json:"frameId,omitempty"`
+}
+
+func NewSetExpressionRequest(args SetExpressionArguments) Request {
+	return struct {
+		request
+		Arguments SetExpressionArguments `json:"arguments"`
+	}{
+		request:   newRequest("setExpression"),
+		Arguments: args,
+	}
+}
+
+type ExceptionInfoArguments struct {
+	ThreadID int `json:"threadId"`
+}
+
+func NewExceptionInfoRequest(args ExceptionInfoArguments) Request {
+	return struct {
+		request
+		Arguments ExceptionInfoArguments `json:"arguments"`
+	}{
+		request:   newRequest("exceptionInfo"),
+		Arguments: args,
+	}
+}
+
+type ModulesArguments struct {
+	StartModule int `json:"startModule,omitempty"`
+	ModuleCount int `json:"moduleCount,omitempty"`
+}
+
+func NewModulesRequest(args ModulesArguments) Request {
+	return struct {
+		request
+		Arguments ModulesArguments `json:"arguments"`
+	}{
+		request:   newRequest("modules"),
+		Arguments: args,
+	}
+}
+
+func NewLoadedSourcesRequest() Request {
+	return newRequest("loadedSources")
+}
+
+type SourceArguments struct {
+	Source          *Source `json:"source,omitempty"`
+	SourceReference int     `json:"sourceReference"`
+}
+
+func NewSourceRequest(args SourceArguments) Request {
+	return struct {
+		request
+		Arguments SourceArguments `json:"arguments"`
+	}{
+		request:   newRequest("source"),
+		Arguments: args,
+	}
+}
+
+type ReadMemoryArguments struct {
+	MemoryReference string `json:"memoryReference"`
+	Offset          int    `json:"offset,omitempty"`
+	Count           int    `json:"count"`
+}
+
+func NewReadMemoryRequest(args ReadMemoryArguments) Request {
+	return struct {
+		request
+		Arguments ReadMemoryArguments `json:"arguments"`
+	}{
+		request:   newRequest("readMemory"),
+		Arguments: args,
+	}
+}
+
+type WriteMemoryArguments struct {
+	MemoryReference string `json:"memoryReference"`
+	Offset          int    `json:"offset,omitempty"`
+	AllowPartial    bool   `json:"allowPartial,omitempty"`
+	// Data is base64-encoded.
+	Data string `json:"data"`
+}
+
+func NewWriteMemoryRequest(args WriteMemoryArguments) Request {
+	return struct {
+		request
+		Arguments WriteMemoryArguments `json:"arguments"`
+	}{
+		request:   newRequest("writeMemory"),
+		Arguments: args,
+	}
+}
+
+type DisassembleArguments struct {
+	MemoryReference   string `json:"memoryReference"`
+	Offset            int    `json:"offset,omitempty"`
+	InstructionOffset int    `json:"instructionOffset,omitempty"`
+	InstructionCount  int    `json:"instructionCount"`
+	ResolveSymbols    bool   `json:"resolveSymbols,omitempty"`
+}
+
+func NewDisassembleRequest(args DisassembleArguments) Request {
+	return struct {
+		request
+		Arguments DisassembleArguments `json:"arguments"`
+	}{
+		request:   newRequest("disassemble"),
+		Arguments: args,
+	}
+}
+
 type StackTraceArguments struct {
-	ThreadID int               `json:"threadId"`
-	Levels   int               `json:"levels"`
-	Format   *StackFrameFormat `json:"format"`
+	ThreadID   int               `json:"threadId"`
+	StartFrame int               `json:"startFrame,omitempty"`
+	Levels     int               `json:"levels"`
+	Format     *StackFrameFormat `json:"format,omitempty"`
 }
 
 func NewStackTraceRequest(args StackTraceArguments) Request {
@@ -112,6 +365,20 @@ func NewStackTraceRequest(args StackTraceArguments) Request {
 	}
 }
 
+type RestartFrameArguments struct {
+	FrameID int `json:"frameId"`
+}
+
+func NewRestartFrameRequest(args RestartFrameArguments) Request {
+	return struct {
+		request
+		Arguments RestartFrameArguments `json:"arguments"`
+	}{
+		request:   newRequest("restartFrame"),
+		Arguments: args,
+	}
+}
+
 func NewLaunchRequest(args map[string]any) Request {
 	return struct {
 		request
@@ -137,6 +404,55 @@ func NewSetBreakpointRequest(args SetBreakpointArguments) Request {
 	}
 }
 
+type BreakpointLocationsArguments struct {
+	Source    Source `json:"source"`
+	Line      int    `json:"line"`
+	Column    *int   `json:"column,omitempty"`
+	EndLine   *int   `json:"endLine,omitempty"`
+	EndColumn *int   `json:"endColumn,omitempty"`
+}
+
+func NewBreakpointLocationsRequest(args BreakpointLocationsArguments) Request {
+	return struct {
+		request
+		Arguments BreakpointLocationsArguments `json:"arguments"`
+	}{
+		request:   newRequest("breakpointLocations"),
+		Arguments: args,
+	}
+}
+
+type GotoTargetsArguments struct {
+	Source Source `json:"source"`
+	Line   int    `json:"line"`
+	Column *int   `json:"column,omitempty"`
+}
+
+func NewGotoTargetsRequest(args GotoTargetsArguments) Request {
+	return struct {
+		request
+		Arguments GotoTargetsArguments `json:"arguments"`
+	}{
+		request:   newRequest("gotoTargets"),
+		Arguments: args,
+	}
+}
+
+type GotoArguments struct {
+	ThreadID int `json:"threadId"`
+	TargetID int `json:"targetId"`
+}
+
+func NewGotoRequest(args GotoArguments) Request {
+	return struct {
+		request
+		Arguments GotoArguments `json:"arguments"`
+	}{
+		request:   newRequest("goto"),
+		Arguments: args,
+	}
+}
+
 type TerminateArguments struct {
 	Restart bool `json:"restart,omitempty"`
 }
@@ -151,6 +467,20 @@ func NewTerminateRequest(args TerminateArguments) Request {
 	}
 }
 
+type TerminateThreadsArguments struct {
+	ThreadIDs []int `json:"threadIds,omitempty"`
+}
+
+func NewTerminateThreadsRequest(args TerminateThreadsArguments) Request {
+	return struct {
+		request
+		Arguments TerminateThreadsArguments `json:"arguments"`
+	}{
+		request:   newRequest("terminateThreads"),
+		Arguments: args,
+	}
+}
+
 type DisconnectArguments struct {
 	Restart           bool `json:"restart,omitempty"`
 	TerminateDebuggee bool `json:"terminateDebuggee,omitempty"`
@@ -185,8 +515,9 @@ func NewCompletionsRequest(args CompletionsArguments) Request {
 }
 
 type NextArguments struct {
-	ThreadID            int    `json:"threadId"`
-	SteppingGranularity string `json:"steppingGranularity,omitempty"`
+	ThreadID     int    `json:"threadId"`
+	SingleThread bool   `json:"singleThread,omitempty"`
+	Granularity  string `json:"granularity,omitempty"`
 }
 
 func NewNextRequest(args NextArguments) Request {
@@ -36,3 +36,63 @@ func TestNewEvaluateRequest(t *testing.T) {
 		t.Errorf("mismatch (-want +got):\n%s", diff)
 	}
 }
+
+func TestNewStepInRequest(t *testing.T) {
+	req := types.NewStepInRequest(types.StepInArguments{
+		ThreadID:     7,
+		SingleThread: true,
+	})
+
+	raw, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]any
+	if err = json.Unmarshal(raw, &got); err != nil {
+		t.Fatal(err)
+	}
+	delete(got, "seq")
+
+	want := map[string]any{
+		"type":    "request",
+		"command": "stepIn",
+		"arguments": map[string]any{
+			"threadId":     float64(7),
+			"singleThread": true,
+		},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestNewReverseContinueRequest(t *testing.T) {
+	req := types.NewReverseContinueRequest(types.ReverseContinueArguments{
+		ThreadID: 3,
+	})
+
+	raw, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]any
+	if err = json.Unmarshal(raw, &got); err != nil {
+		t.Fatal(err)
+	}
+	delete(got, "seq")
+
+	want := map[string]any{
+		"type":    "request",
+		"command": "reverseContinue",
+		"arguments": map[string]any{
+			"threadId": float64(3),
+		},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
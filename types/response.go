@@ -34,13 +34,94 @@ func (r ErrorResponse) Error() string {
 }
 
 type EvaluateResponse struct {
-	Result string `json:"result"`
+	Result             string                    `json:"result"`
+	Type               string                    `json:"type,omitempty"`
+	PresentationHint   *VariablePresentationHint `json:"presentationHint,omitempty"`
+	VariablesReference int                       `json:"variablesReference"`
+	NamedVariables     *int                      `json:"namedVariables,omitempty"`
+	IndexedVariables   *int                      `json:"indexedVariables,omitempty"`
+	MemoryReference    *string                   `json:"memoryReference,omitempty"`
+}
+
+type ScopesResponse struct {
+	Scopes []Scope `json:"scopes"`
+}
+
+type VariablesResponse struct {
+	Variables []Variable `json:"variables"`
+}
+
+// SetVariableResponse is also the body of a setExpression response.
+type SetVariableResponse struct {
+	Value              string `json:"value"`
+	Type               string `json:"type,omitempty"`
+	VariablesReference int    `json:"variablesReference,omitempty"`
+	NamedVariables     *int   `json:"namedVariables,omitempty"`
+	IndexedVariables   *int   `json:"indexedVariables,omitempty"`
+}
+
+type ExceptionInfoResponse struct {
+	ExceptionID string            `json:"exceptionId"`
+	Description string            `json:"description,omitempty"`
+	BreakMode   string            `json:"breakMode"`
+	Details     *ExceptionDetails `json:"details,omitempty"`
+}
+
+type ModulesResponse struct {
+	Modules      []Module `json:"modules"`
+	TotalModules *int     `json:"totalModules,omitempty"`
+}
+
+type LoadedSourcesResponse struct {
+	Sources []Source `json:"sources"`
+}
+
+type SourceResponse struct {
+	Content  string `json:"content"`
+	MimeType string `json:"mimeType,omitempty"`
+}
+
+type ReadMemoryResponse struct {
+	Address         string `json:"address"`
+	UnreadableBytes int    `json:"unreadableBytes,omitempty"`
+	// Data is base64-encoded.
+	Data string `json:"data,omitempty"`
+}
+
+type WriteMemoryResponse struct {
+	Offset       *int `json:"offset,omitempty"`
+	BytesWritten *int `json:"bytesWritten,omitempty"`
+}
+
+type DisassembleResponse struct {
+	Instructions []DisassembledInstruction `json:"instructions"`
 }
 
 type ThreadsResponse struct {
 	Threads []Thread `json:"threads"`
 }
 
+type SetBreakpointsResponse struct {
+	Breakpoints []Breakpoint `json:"breakpoints"`
+}
+
+type BreakpointLocationsResponse struct {
+	Breakpoints []BreakpointLocation `json:"breakpoints"`
+}
+
+type StepInTargetsResponse struct {
+	Targets []StepInTarget `json:"targets"`
+}
+
+type GotoTargetsResponse struct {
+	Targets []GotoTarget `json:"targets"`
+}
+
+type StackTraceResponse struct {
+	StackFrames []StackFrame `json:"stackFrames"`
+	TotalFrames *int         `json:"totalFrames,omitempty"`
+}
+
 type CompletionsResponse struct {
 	Targets []CompletionItem `json:"targets"`
 }
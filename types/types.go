@@ -1,23 +1,122 @@
 package types
 
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
 type Source struct {
 	Name *string `json:"name,omitempty"`
 	Path *string `json:"path,omitempty"`
+	// SourceReference is set, and greater than 0, if the source's content has
+	// to be fetched from the debug adapter with a source request.
+	SourceReference *int `json:"sourceReference,omitempty"`
+	// AdapterData is opaque, and sent back to the debug adapter as is.
+	AdapterData json.RawMessage `json:"adapterData,omitempty"`
+}
+
+// Reference returns the source's reference, or 0 if its content should be read
+// from its path.
+func (s Source) Reference() int {
+	if s.SourceReference == nil {
+		return 0
+	}
+	return *s.SourceReference
 }
 
 type SourceBreakpoint struct {
-	Line int `json:"line"`
+	Line         int    `json:"line"`
+	Column       *int   `json:"column,omitempty"`
+	Condition    string `json:"condition,omitempty"`
+	HitCondition string `json:"hitCondition,omitempty"`
+	LogMessage   string `json:"logMessage,omitempty"`
+}
+
+// https://microsoft.github.io/debug-adapter-protocol/specification#Types_Breakpoint
+type Breakpoint struct {
+	ID        *int    `json:"id,omitempty"`
+	Verified  bool    `json:"verified"`
+	Message   *string `json:"message,omitempty"`
+	Source    *Source `json:"source,omitempty"`
+	Line      *int    `json:"line,omitempty"`
+	Column    *int    `json:"column,omitempty"`
+	EndLine   *int    `json:"endLine,omitempty"`
+	EndColumn *int    `json:"endColumn,omitempty"`
+}
+
+// https://microsoft.github.io/debug-adapter-protocol/specification#Types_BreakpointLocation
+type BreakpointLocation struct {
+	Line      int  `json:"line"`
+	Column    *int `json:"column,omitempty"`
+	EndLine   *int `json:"endLine,omitempty"`
+	EndColumn *int `json:"endColumn,omitempty"`
+}
+
+// https://microsoft.github.io/debug-adapter-protocol/specification#Types_StepInTarget
+type StepInTarget struct {
+	ID        int    `json:"id"`
+	Label     string `json:"label"`
+	Line      *int   `json:"line,omitempty"`
+	Column    *int   `json:"column,omitempty"`
+	EndLine   *int   `json:"endLine,omitempty"`
+	EndColumn *int   `json:"endColumn,omitempty"`
+}
+
+// https://microsoft.github.io/debug-adapter-protocol/specification#Types_GotoTarget
+type GotoTarget struct {
+	ID                          int     `json:"id"`
+	Label                       string  `json:"label"`
+	Line                        int     `json:"line"`
+	Column                      *int    `json:"column,omitempty"`
+	EndLine                     *int    `json:"endLine,omitempty"`
+	EndColumn                   *int    `json:"endColumn,omitempty"`
+	InstructionPointerReference *string `json:"instructionPointerReference,omitempty"`
 }
 
 type StackFrame struct {
-	ID     int     `json:"id"`
-	Source *Source `json:"source"`
-	Line   int     `json:"line"`
-	Column int     `json:"column"`
+	ID         int     `json:"id"`
+	Name       string  `json:"name"`
+	Source     *Source `json:"source"`
+	Line       int     `json:"line"`
+	Column     int     `json:"column"`
+	CanRestart *bool   `json:"canRestart,omitempty"`
+	// InstructionPointerReference is the memory reference of the frame's
+	// current instruction, which can be disassembled.
+	InstructionPointerReference *string `json:"instructionPointerReference,omitempty"`
+}
+
+// https://microsoft.github.io/debug-adapter-protocol/specification#Types_DisassembledInstruction
+type DisassembledInstruction struct {
+	Address          string `json:"address"`
+	InstructionBytes string `json:"instructionBytes,omitempty"`
+	Instruction      string `json:"instruction"`
+	Symbol           string `json:"symbol,omitempty"`
+	// Location is omitted when it's the same as the previous instruction's.
+	Location  *Source `json:"location,omitempty"`
+	Line      *int    `json:"line,omitempty"`
+	Column    *int    `json:"column,omitempty"`
+	EndLine   *int    `json:"endLine,omitempty"`
+	EndColumn *int    `json:"endColumn,omitempty"`
+	// PresentationHint is "normal" or "invalid".
+	PresentationHint string `json:"presentationHint,omitempty"`
+}
+
+// https://microsoft.github.io/debug-adapter-protocol/specification#Types_ValueFormat
+type ValueFormat struct {
+	Hex *bool `json:"hex,omitempty"`
 }
 
+// https://microsoft.github.io/debug-adapter-protocol/specification#Types_StackFrameFormat
 type StackFrameFormat struct {
-	Line *bool `json:"line"`
+	ValueFormat
+	Parameters      *bool `json:"parameters,omitempty"`
+	ParameterTypes  *bool `json:"parameterTypes,omitempty"`
+	ParameterNames  *bool `json:"parameterNames,omitempty"`
+	ParameterValues *bool `json:"parameterValues,omitempty"`
+	Line            *bool `json:"line"`
+	Module          *bool `json:"module,omitempty"`
+	IncludeAll      *bool `json:"includeAll,omitempty"`
 }
 
 type Capabilities struct {
@@ -63,7 +162,14 @@ type Capabilities struct {
 }
 
 type ExceptionBreakpointsFilter struct{}
-type ColumnDescriptor struct{}
+// https://microsoft.github.io/debug-adapter-protocol/specification#Types_ColumnDescriptor
+type ColumnDescriptor struct {
+	AttributeName string `json:"attributeName"`
+	Label         string `json:"label"`
+	Format        string `json:"format,omitempty"`
+	Type          string `json:"type,omitempty"`
+	Width         int    `json:"width,omitempty"`
+}
 type ChecksumAlgorithm struct{}
 
 type Thread struct {
@@ -82,3 +188,77 @@ type CompletionItem struct {
 	SelectionStart  *int   `json:"selectionStart,omitempty"`
 	SelectionLength int    `json:"selectionLength,omitempty"`
 }
+
+// https://microsoft.github.io/debug-adapter-protocol/specification#Types_Scope
+type Scope struct {
+	Name               string  `json:"name"`
+	PresentationHint   string  `json:"presentationHint,omitempty"`
+	VariablesReference int     `json:"variablesReference"`
+	NamedVariables     *int    `json:"namedVariables,omitempty"`
+	IndexedVariables   *int    `json:"indexedVariables,omitempty"`
+	Expensive          bool    `json:"expensive"`
+	Source             *Source `json:"source,omitempty"`
+	Line               *int    `json:"line,omitempty"`
+}
+
+// https://microsoft.github.io/debug-adapter-protocol/specification#Types_Variable
+type Variable struct {
+	Name               string                    `json:"name"`
+	Value              string                    `json:"value"`
+	Type               string                    `json:"type,omitempty"`
+	PresentationHint   *VariablePresentationHint `json:"presentationHint,omitempty"`
+	EvaluateName       string                    `json:"evaluateName,omitempty"`
+	VariablesReference int                       `json:"variablesReference"`
+	NamedVariables     *int                      `json:"namedVariables,omitempty"`
+	IndexedVariables   *int                      `json:"indexedVariables,omitempty"`
+	MemoryReference    *string                   `json:"memoryReference,omitempty"`
+}
+
+// https://microsoft.github.io/debug-adapter-protocol/specification#Types_VariablePresentationHint
+type VariablePresentationHint struct {
+	Kind       string   `json:"kind,omitempty"`
+	Attributes []string `json:"attributes,omitempty"`
+	Visibility string   `json:"visibility,omitempty"`
+	Lazy       bool     `json:"lazy,omitempty"`
+}
+
+// https://microsoft.github.io/debug-adapter-protocol/specification#Types_ExceptionDetails
+type ExceptionDetails struct {
+	Message        string             `json:"message,omitempty"`
+	TypeName       string             `json:"typeName,omitempty"`
+	FullTypeName   string             `json:"fullTypeName,omitempty"`
+	EvaluateName   string             `json:"evaluateName,omitempty"`
+	StackTrace     string             `json:"stackTrace,omitempty"`
+	InnerException []ExceptionDetails `json:"innerException,omitempty"`
+}
+
+// https://microsoft.github.io/debug-adapter-protocol/specification#Types_Module
+type Module struct {
+	// ID is either a number or a string in the protocol, so it's kept as a
+	// string.
+	ID   string
+	Name string
+	Path string
+	// Attributes holds every field of the module as a string, including
+	// those named by AdditionalModuleColumns.
+	Attributes map[string]string
+}
+
+func (m *Module) UnmarshalJSON(b []byte) error {
+	var fields map[string]any
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&fields); err != nil {
+		return err
+	}
+	m.Attributes = make(map[string]string, len(fields))
+	for key, value := range fields {
+		if s, ok := value.(string); ok {
+			m.Attributes[key] = s
+		} else {
+			m.Attributes[key] = fmt.Sprint(value)
+		}
+	}
+	m.ID, m.Name, m.Path = m.Attributes["id"], m.Attributes["name"], m.Attributes["path"]
+	return nil
+}
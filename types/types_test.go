@@ -0,0 +1,32 @@
+package types_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/dradtke/debug-console/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestModuleUnmarshalJSON(t *testing.T) {
+	var got types.Module
+	raw := `{"id": 12345678, "name": "libc.so.6", "path": "/usr/lib/libc.so.6", "isOptimized": true}`
+	if err := json.Unmarshal([]byte(raw), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	want := types.Module{
+		ID:   "12345678",
+		Name: "libc.so.6",
+		Path: "/usr/lib/libc.so.6",
+		Attributes: map[string]string{
+			"id":          "12345678",
+			"name":        "libc.so.6",
+			"path":        "/usr/lib/libc.so.6",
+			"isOptimized": "true",
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}